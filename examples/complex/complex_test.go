@@ -28,8 +28,8 @@ func TestComplexImports(t *testing.T) {
 	}
 
 	// Проверяем аннотации пакета
-	if result.Annotations["name"] != "ComplexImports" {
-		t.Errorf("Expected package name 'ComplexImports', got '%s'", result.Annotations["name"])
+	if result.Annotations.Get("name") != "ComplexImports" {
+		t.Errorf("Expected package name 'ComplexImports', got '%s'", result.Annotations.Get("name"))
 	}
 
 	// Проверяем интерфейс
@@ -312,8 +312,8 @@ func TestAdvancedAnnotations(t *testing.T) {
 	}
 
 	for key, expectedValue := range expectedPackageAnnotations {
-		if result.Annotations[key] != expectedValue {
-			t.Errorf("Package annotation '%s' expected '%s', got '%s'", key, expectedValue, result.Annotations[key])
+		if result.Annotations.Get(key) != expectedValue {
+			t.Errorf("Package annotation '%s' expected '%s', got '%s'", key, expectedValue, result.Annotations.Get(key))
 		}
 	}
 
@@ -335,8 +335,8 @@ func TestAdvancedAnnotations(t *testing.T) {
 	}
 
 	for key, expectedValue := range expectedInterfaceAnnotations {
-		if service.Annotations[key] != expectedValue {
-			t.Errorf("Interface annotation '%s' expected '%s', got '%s'", key, expectedValue, service.Annotations[key])
+		if service.Annotations.Get(key) != expectedValue {
+			t.Errorf("Interface annotation '%s' expected '%s', got '%s'", key, expectedValue, service.Annotations.Get(key))
 		}
 	}
 
@@ -662,8 +662,8 @@ func TestCornerCases(t *testing.T) {
 	}
 
 	for key, expectedValue := range expectedPackageAnnotations {
-		if result.Annotations[key] != expectedValue {
-			t.Errorf("Package annotation '%s' expected '%s', got '%s'", key, expectedValue, result.Annotations[key])
+		if result.Annotations.Get(key) != expectedValue {
+			t.Errorf("Package annotation '%s' expected '%s', got '%s'", key, expectedValue, result.Annotations.Get(key))
 		}
 	}
 }
@@ -727,8 +727,8 @@ func TestEdgeCases(t *testing.T) {
 	}
 
 	// Проверяем аннотации пакета
-	if result.Annotations["name"] != "EdgeCases" {
-		t.Errorf("Expected package name 'EdgeCases', got '%s'", result.Annotations["name"])
+	if result.Annotations.Get("name") != "EdgeCases" {
+		t.Errorf("Expected package name 'EdgeCases', got '%s'", result.Annotations.Get("name"))
 	}
 
 	// Проверяем интерфейс с множественными аннотациями
@@ -745,8 +745,8 @@ func TestEdgeCases(t *testing.T) {
 			}
 
 			for key, expectedValue := range expectedAnnotations {
-				if iface.Annotations[key] != expectedValue {
-					t.Errorf("Interface annotation '%s' expected '%s', got '%s'", key, expectedValue, iface.Annotations[key])
+				if iface.Annotations.Get(key) != expectedValue {
+					t.Errorf("Interface annotation '%s' expected '%s', got '%s'", key, expectedValue, iface.Annotations.Get(key))
 				}
 			}
 		}
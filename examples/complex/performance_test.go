@@ -284,3 +284,30 @@ func TestParserReuse(t *testing.T) {
 		}
 	})
 }
+
+// BenchmarkParsePackage измеряет время парсинга пакетов из сценариев TestPerformance
+func BenchmarkParsePackage(b *testing.B) {
+	ctx := context.Background()
+	p := parser.NewParser()
+
+	packages := []string{
+		"./complex_imports",
+		"./cyclic_structures",
+		"./generic_types",
+		"./nested_types",
+		"./advanced_annotations",
+		"./corner_cases",
+		"./edge_cases",
+	}
+
+	for _, packagePath := range packages {
+		b.Run(filepath.Base(packagePath), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := p.ParsePackage(ctx, packagePath); err != nil {
+					b.Fatalf("Failed to parse package %s: %v", packagePath, err)
+				}
+			}
+		})
+	}
+}
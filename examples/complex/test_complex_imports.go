@@ -42,11 +42,11 @@ func TestComplexImportsSimple(t *testing.T) {
 	}
 
 	// Проверяем аннотации пакета
-	if result.Annotations["name"] != "ComplexImports" {
-		t.Errorf("Expected package name 'ComplexImports', got '%s'", result.Annotations["name"])
+	if result.Annotations.Get("name") != "ComplexImports" {
+		t.Errorf("Expected package name 'ComplexImports', got '%s'", result.Annotations.Get("name"))
 	}
 
-	if result.Annotations["version"] != "2.0" {
-		t.Errorf("Expected package version '2.0', got '%s'", result.Annotations["version"])
+	if result.Annotations.Get("version") != "2.0" {
+		t.Errorf("Expected package version '2.0', got '%s'", result.Annotations.Get("version"))
 	}
 }
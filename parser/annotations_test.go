@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+const typedAnnotationsFile = `package service
+
+import "context"
+
+// @asti name=TypedService timeout=30s retry=true attempts=3 ratio=0.5
+// @asti tags=complex,advanced title="Typed service" port=abc
+type TypedService interface {
+	Run(ctx context.Context) (err error)
+}
+`
+
+// TestTypedAnnotations проверяет распознавание типов значений и ошибки с позициями
+func TestTypedAnnotations(t *testing.T) {
+
+	result := parseService(t, typedAnnotationsFile)
+	if len(result.Interfaces) != 1 {
+		t.Fatalf("Expected 1 interface, got %d", len(result.Interfaces))
+	}
+	annotations := result.Interfaces[0].Annotations
+
+	for _, test := range []struct {
+		key      string
+		kind     models.ValueKind
+		value    func(annotations models.Annotations, key string) (value any, err error)
+		expected any
+	}{
+		{key: "name", kind: models.KindString, expected: "TypedService"},
+		{key: "timeout", kind: models.KindDuration, expected: 30 * time.Second, value: func(annotations models.Annotations, key string) (value any, err error) {
+			return annotations.Duration(key)
+		}},
+		{key: "retry", kind: models.KindBool, expected: true, value: func(annotations models.Annotations, key string) (value any, err error) {
+			return annotations.Bool(key)
+		}},
+		{key: "attempts", kind: models.KindInt, expected: 3, value: func(annotations models.Annotations, key string) (value any, err error) {
+			return annotations.Int(key)
+		}},
+		{key: "ratio", kind: models.KindFloat, expected: 0.5, value: func(annotations models.Annotations, key string) (value any, err error) {
+			return annotations.Float(key)
+		}},
+		{key: "tags", kind: models.KindList, expected: "[complex advanced]", value: func(annotations models.Annotations, key string) (value any, err error) {
+			var list []string
+			list, err = annotations.List(key)
+			return fmt.Sprint(list), err
+		}},
+		{key: "title", kind: models.KindString, expected: "Typed service"},
+	} {
+		if value, _ := annotations.Lookup(test.key); value.Kind != test.kind {
+			t.Errorf("Expected %s to be %s, got %s", test.key, test.kind, value.Kind)
+		}
+		var actual any = annotations.Get(test.key)
+		var err error
+		if test.value != nil {
+			actual, err = test.value(annotations, test.key)
+		}
+		if err != nil || actual != test.expected {
+			t.Errorf("Expected %s %v, got %v, %v", test.key, test.expected, actual, err)
+		}
+	}
+	if title, _ := annotations.Lookup("title"); title.Raw != `"Typed service"` {
+		t.Errorf("Expected raw quoted title, got %+v", title)
+	}
+
+	_, err := annotations.Int("port")
+	var valueErr *models.ValueError
+	if !errors.As(err, &valueErr) {
+		t.Fatalf("Expected ValueError for port, got %v", err)
+	}
+	if err.Error() != `service.go:6:59: annotation port: invalid integer "abc"` {
+		t.Errorf("Unexpected error text %q", err.Error())
+	}
+	if _, err = annotations.Int("missing"); !errors.Is(err, models.ErrAnnotationNotFound) {
+		t.Errorf("Expected ErrAnnotationNotFound, got %v", err)
+	}
+
+	// JSON сохраняет текст, вид и позицию значений и порядок ключей
+	data, restored := roundtrip(t, result)
+	var compact bytes.Buffer
+	if err = json.Compact(&compact, data); err != nil {
+		t.Fatalf("Failed to compact JSON: %v", err)
+	}
+	if !strings.Contains(compact.String(), `"port":{"raw":"abc","kind":"string","position":{"file":"service.go","line":6,"column":59}}`) {
+		t.Errorf("Unexpected annotations JSON in %s", data)
+	}
+	restoredAnnotations := restored.Interfaces[0].Annotations
+	timeout, _ := restoredAnnotations.Duration("timeout")
+	_, portErr := restoredAnnotations.Int("port")
+	checkExpectations(t, []expectation{
+		{name: "keys after roundtrip", actual: fmt.Sprint(restoredAnnotations.Keys()), expected: fmt.Sprint(annotations.Keys())},
+		{name: "timeout after roundtrip", actual: timeout.String(), expected: "30s"},
+		{name: "port error after roundtrip", actual: fmt.Sprint(portErr), expected: `service.go:6:59: annotation port: invalid integer "abc"`},
+	})
+}
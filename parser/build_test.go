@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"context"
+	"go/build"
+	"testing"
+)
+
+// TestBuildContextFileSelection проверяет отбор файлов по GOOS/GOARCH и тегам сборки
+func TestBuildContextFileSelection(t *testing.T) {
+
+	sources := map[string][]byte{
+		"go.mod": []byte("module github.com/test/build\n\ngo 1.24\n"),
+		"service.go": []byte(`package service
+
+import "context"
+
+// @asti name=PlatformService
+type PlatformService interface {
+	Info(ctx context.Context) (platform Platform, err error)
+}
+`),
+		"platform_linux.go":   []byte("package service\n\ntype Platform struct {\n\tLinux bool\n}\n"),
+		"platform_windows.go": []byte("package service\n\ntype Platform struct {\n\tWindows bool\n}\n"),
+		"platform_other.go":   []byte("//go:build !linux && !windows\n\npackage service\n\ntype Platform struct {\n\tOther bool\n}\n"),
+		"extra.go":            []byte("//go:build extra\n\npackage service\n\ntype Extra struct{}\n"),
+		"service_test.go":     []byte("package service\n\ntype TestOnly struct{}\n"),
+	}
+
+	testCases := []struct {
+		goos          string
+		tags          []string
+		expectedField string
+		expectExtra   bool
+	}{
+		{"linux", nil, "Linux", false},
+		{"windows", nil, "Windows", false},
+		{"darwin", []string{"extra"}, "Other", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.goos, func(t *testing.T) {
+			buildContext := build.Default
+			buildContext.GOOS = tc.goos
+			buildContext.GOARCH = "amd64"
+			buildContext.BuildTags = tc.tags
+			p := NewParser(WithBuildContext(buildContext))
+
+			result, err := p.ParseSources(context.Background(), sources)
+			if err != nil {
+				t.Fatalf("Failed to parse sources: %v", err)
+			}
+
+			platform, found := result.Types["service.Platform"]
+			if !found {
+				t.Fatal("Type service.Platform not found")
+			}
+			if len(platform.Fields) != 1 || platform.Fields[0].Name != tc.expectedField {
+				t.Errorf("Expected Platform field %s, got %+v", tc.expectedField, platform.Fields)
+			}
+			if _, found = result.Types["service.Extra"]; found != tc.expectExtra {
+				t.Errorf("Expected Extra presence %v, got %v", tc.expectExtra, found)
+			}
+			if _, found = result.Types["service.TestOnly"]; found {
+				t.Error("Types from _test.go files should not be collected")
+			}
+
+			if result.Build == nil || result.Build.GOOS != tc.goos || result.Build.GOARCH != "amd64" {
+				t.Errorf("Unexpected build info: %+v", result.Build)
+			}
+			if len(result.Build.Tags) != len(tc.tags) {
+				t.Errorf("Expected build tags %v, got %v", tc.tags, result.Build.Tags)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package parser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/build"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/seniorGolang/asti/parser/models"
+	"github.com/seniorGolang/asti/parser/pipeline"
+)
+
+// cacheVersion меняется при изменении формата результата, чтобы не читать устаревшие записи
+const cacheVersion = "asti-cache-v6"
+
+// CacheStats статистика работы кэша результатов разбора
+type CacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+	Size    int64 `json:"size"`
+}
+
+// packageCache хранит сериализованные результаты разбора пакетов в директории на диске.
+// Запись лежит в <dir>/<хэш пути пакета>/<ключ>.json, где ключ — хэш содержимого файлов пакета,
+// go.mod и опций парсера.
+type packageCache struct {
+	dir    string
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func newPackageCache(dir string) (cache *packageCache) {
+
+	cache = &packageCache{dir: dir}
+	return
+}
+
+// packageDir возвращает директорию записей пакета
+func (c *packageCache) packageDir(packagePath string) (dir string) {
+
+	sum := sha256.Sum256([]byte(packagePath))
+	dir = filepath.Join(c.dir, hex.EncodeToString(sum[:8]))
+	return
+}
+
+// load возвращает сохраненный результат по ключу
+func (c *packageCache) load(packagePath string, key string) (pkg *models.Package, found bool) {
+
+	content, err := os.ReadFile(filepath.Join(c.packageDir(packagePath), key+".json"))
+	if err == nil {
+		pkg, err = pipeline.NewStageSerialization().FromJSON(content)
+	}
+	if found = err == nil; found {
+		c.hits.Add(1)
+	} else {
+		pkg = nil
+		c.misses.Add(1)
+	}
+	return
+}
+
+// store сохраняет результат и удаляет устаревшие записи пакета
+func (c *packageCache) store(packagePath string, key string, pkg *models.Package) (err error) {
+
+	var content []byte
+	if content, err = pipeline.NewStageSerialization().ToJSON(pkg); err != nil {
+		return
+	}
+	dir := c.packageDir(packagePath)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	var entries []fs.DirEntry
+	if entries, err = os.ReadDir(dir); err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.Name() != key+".json" {
+			_ = os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+	// Пишем во временный файл и переименовываем, чтобы параллельные читатели не увидели неполную запись
+	var tmp *os.File
+	if tmp, err = os.CreateTemp(dir, key+".*.tmp"); err != nil {
+		return
+	}
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err = os.Rename(tmp.Name(), filepath.Join(dir, key+".json")); err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return
+}
+
+// invalidate удаляет записи указанных пакетов, без аргументов — весь кэш
+func (c *packageCache) invalidate(packagePaths ...string) (err error) {
+
+	if len(packagePaths) == 0 {
+		var entries []fs.DirEntry
+		if entries, err = os.ReadDir(c.dir); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				err = nil
+			}
+			return
+		}
+		for _, entry := range entries {
+			if err = os.RemoveAll(filepath.Join(c.dir, entry.Name())); err != nil {
+				return
+			}
+		}
+		return
+	}
+	for _, packagePath := range packagePaths {
+		if err = os.RemoveAll(c.packageDir(packagePath)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// stats возвращает счетчики попаданий и размер кэша на диске
+func (c *packageCache) stats() (stats CacheStats) {
+
+	stats.Hits = c.hits.Load()
+	stats.Misses = c.misses.Load()
+	_ = filepath.WalkDir(c.dir, func(path string, entry fs.DirEntry, err error) error {
+
+		if err != nil || entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			return nil
+		}
+		if info, infoErr := entry.Info(); infoErr == nil {
+			stats.Entries++
+			stats.Size += info.Size()
+		}
+		return nil
+	})
+	return
+}
+
+// parseCached возвращает результат из кэша или разбирает пакет и сохраняет результат.
+// Ошибки записи в кэш не прерывают разбор.
+func (p *Parser) parseCached(ctx context.Context, fileSystem pipeline.FileSystem, packagePath string) (result *models.Package, err error) {
+
+	if !p.cacheable() {
+		result, err = p.parse(ctx, fileSystem, packagePath)
+		return
+	}
+	statFileSystem := fileSystem
+	if statFileSystem == nil {
+		statFileSystem = pipeline.OSFileSystem{}
+	}
+	var key string
+	if key, err = p.cacheKey(statFileSystem, p.currentBuildContext(), packagePath); err != nil {
+		result, err = p.parse(ctx, fileSystem, packagePath)
+		return
+	}
+	result, found := p.cache.load(packagePath, key)
+	trace.SpanFromContext(ctx).SetAttributes(attributeCacheHit.Bool(found))
+	if found {
+		return
+	}
+	if result, err = p.parse(ctx, fileSystem, packagePath); err != nil {
+		return
+	}
+	_ = p.cache.store(packagePath, key, result)
+	return
+}
+
+// cacheable сообщает, описывает ли ключ кэша все, что влияет на результат. Собственные этапы,
+// хуки и middleware, правила фильтрации и валидации и парсеры аннотаций без models.AnnotationCacheKeyer
+// ключом не описываются, а при проверке типов результат зависит от исходников других пакетов,
+// поэтому в этих случаях кэш не используется.
+func (p *Parser) cacheable() (ok bool) {
+
+	ok = !p.typeChecking && !p.customStages && len(p.filterRules) == 0 && len(p.validationRules) == 0 &&
+		len(p.hooks) == 0 && len(p.middleware) == 0
+	if _, keyed := annotationParserKey(p.annotationParser); !keyed {
+		ok = false
+	}
+	for _, namespace := range p.namespaces {
+		if _, keyed := annotationParserKey(namespace.Parser); !keyed {
+			ok = false
+		}
+	}
+	return
+}
+
+// cacheKey вычисляет ключ кэша по содержимому Go файлов пакета, go.mod и опциям парсера.
+// Изменения в других пакетах ключ не меняют, поэтому с проверкой типов кэш не используется.
+func (p *Parser) cacheKey(fileSystem pipeline.FileSystem, buildContext *build.Context, packagePath string) (key string, err error) {
+
+	hasher := sha256.New()
+	writeField(hasher, cacheVersion)
+	writeField(hasher, p.annotationPrefix)
+	parserKey, _ := annotationParserKey(p.annotationParser)
+	writeField(hasher, parserKey)
+	for _, namespace := range p.namespaces {
+		parserKey, _ = annotationParserKey(namespace.Parser)
+		writeField(hasher, namespace.Name)
+		writeField(hasher, parserKey)
+	}
+	writeField(hasher, fmt.Sprint(p.partial))
+	writeField(hasher, buildContext.GOOS)
+	writeField(hasher, buildContext.GOARCH)
+	writeField(hasher, fmt.Sprint(buildContext.CgoEnabled))
+	writeField(hasher, strings.Join(buildContext.BuildTags, ","))
+	writeField(hasher, strings.Join(buildContext.ReleaseTags, ","))
+	writeField(hasher, strings.Join(p.Stages(), ","))
+	if p.schema != nil {
+		schema, _ := json.Marshal(p.schema)
+		writeField(hasher, string(schema))
+	}
+	if p.inheritance != nil {
+		rules, _ := json.Marshal(p.inheritance)
+		writeField(hasher, string(rules))
+	}
+	writeField(hasher, packagePath)
+
+	var entries []fs.DirEntry
+	if entries, err = fileSystem.ReadDir(packagePath); err != nil {
+		err = fmt.Errorf("failed to read directory %s: %w", packagePath, err)
+		return
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") && !strings.HasSuffix(entry.Name(), "_test.go") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var content []byte
+		if content, err = fileSystem.ReadFile(filepath.Join(packagePath, name)); err != nil {
+			return
+		}
+		writeField(hasher, name)
+		writeField(hasher, string(content))
+	}
+
+	for dir := packagePath; ; dir = filepath.Dir(dir) {
+		if content, readErr := fileSystem.ReadFile(filepath.Join(dir, "go.mod")); readErr == nil {
+			writeField(hasher, dir)
+			writeField(hasher, string(content))
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	key = hex.EncodeToString(hasher.Sum(nil))
+	return
+}
+
+// annotationParserKey описывает парсер аннотаций для ключа кэша: тип и models.AnnotationCacheKeyer.
+// Для парсера без CacheKey ok равно false.
+func annotationParserKey(annotationParser models.AnnotationParser) (key string, ok bool) {
+
+	keyer, ok := annotationParser.(models.AnnotationCacheKeyer)
+	if !ok {
+		return
+	}
+	key = fmt.Sprintf("%T:%s", annotationParser, keyer.CacheKey())
+	return
+}
+
+// writeField добавляет в хэш значение с длиной, чтобы соседние поля не склеивались
+func writeField(hasher hash.Hash, value string) {
+
+	_, _ = fmt.Fprintf(hasher, "%d:%s;", len(value), value)
+}
+
+// CacheStats возвращает статистику кэша результатов (нулевую, если кэш не включен)
+func (p *Parser) CacheStats() (stats CacheStats) {
+
+	if p.cache != nil {
+		stats = p.cache.stats()
+	}
+	return
+}
+
+// InvalidateCache удаляет из кэша записи указанных пакетов, без аргументов — все записи
+func (p *Parser) InvalidateCache(packagePaths ...string) (err error) {
+
+	if p.cache == nil {
+		return
+	}
+	absPaths := make([]string, 0, len(packagePaths))
+	for _, packagePath := range packagePaths {
+		var absPath string
+		if absPath, err = filepath.Abs(packagePath); err != nil {
+			err = fmt.Errorf("failed to get absolute path: %w", err)
+			return
+		}
+		absPaths = append(absPaths, absPath)
+	}
+	err = p.cache.invalidate(absPaths...)
+	return
+}
@@ -0,0 +1,257 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+	"github.com/seniorGolang/asti/parser/pipeline"
+)
+
+// writeCacheTestModule создает временный модуль с одним пакетом
+func writeCacheTestModule(t *testing.T) (packageDir string) {
+
+	t.Helper()
+	root := t.TempDir()
+	packageDir = filepath.Join(root, "service")
+	if err := os.MkdirAll(packageDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module github.com/test/cache\n\ngo 1.24\n")
+	writeTestFile(t, filepath.Join(packageDir, "service.go"), sourcesServiceFile)
+	return
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestParsePackageCache проверяет попадания, промахи и инвалидацию кэша
+func TestParsePackageCache(t *testing.T) {
+
+	ctx := context.Background()
+	packageDir := writeCacheTestModule(t)
+	cacheDir := t.TempDir()
+	p := NewParser(WithCache(cacheDir))
+
+	first, err := p.ParsePackageToJSON(ctx, packageDir)
+	if err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+	second, err := p.ParsePackageToJSON(ctx, packageDir)
+	if err != nil {
+		t.Fatalf("Failed to parse cached package: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("Cached result differs from parsed result")
+	}
+	stats := p.CacheStats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 || stats.Size == 0 {
+		t.Errorf("Unexpected cache stats after repeated parse: %+v", stats)
+	}
+
+	// Другой парсер с тем же каталогом кэша использует сохраненный результат
+	if _, err = NewParser(WithCache(cacheDir)).ParsePackage(ctx, packageDir); err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+
+	// Изменение файла пакета меняет ключ и заменяет запись
+	writeTestFile(t, filepath.Join(packageDir, "extra.go"), "package service\n\nimport \"context\"\n\n// @asti name=Extra\ntype Extra interface {\n\tRun(ctx context.Context) (err error)\n}\n")
+	result, err := p.ParsePackage(ctx, packageDir)
+	if err != nil {
+		t.Fatalf("Failed to parse changed package: %v", err)
+	}
+	if len(result.Interfaces) != 2 {
+		t.Errorf("Expected 2 interfaces after change, got %d", len(result.Interfaces))
+	}
+	if stats = p.CacheStats(); stats.Misses != 2 || stats.Entries != 1 {
+		t.Errorf("Unexpected cache stats after change: %+v", stats)
+	}
+
+	// Другие опции парсера не используют чужие записи
+	if _, err = NewParser(WithCache(cacheDir), WithAnnotationPrefix("@other")).ParsePackage(ctx, packageDir); err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+	if _, err = p.ParsePackage(ctx, packageDir); err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+	if stats = p.CacheStats(); stats.Misses != 3 {
+		t.Errorf("Expected a miss after parsing with other options, got %+v", stats)
+	}
+
+	if err = p.InvalidateCache(packageDir); err != nil {
+		t.Fatalf("Failed to invalidate cache: %v", err)
+	}
+	if stats = p.CacheStats(); stats.Entries != 0 {
+		t.Errorf("Expected empty cache after invalidation, got %+v", stats)
+	}
+	if _, err = p.ParsePackage(ctx, packageDir); err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+	if err = p.InvalidateCache(); err != nil {
+		t.Fatalf("Failed to clear cache: %v", err)
+	}
+	if stats = p.CacheStats(); stats.Entries != 0 || stats.Misses != 4 {
+		t.Errorf("Unexpected cache stats after clearing: %+v", stats)
+	}
+}
+
+// TestParsePackageCacheRoundTrip проверяет, что результат из кэша совпадает с результатом разбора
+func TestParsePackageCacheRoundTrip(t *testing.T) {
+
+	expected := expectedPackagesJSON(t)
+	p := NewParser(WithCache(t.TempDir()))
+	for round := 0; round < 2; round++ {
+		for _, packagePath := range concurrencyTestPackages {
+			jsonData, err := p.ParsePackageToJSON(context.Background(), packagePath)
+			if err != nil {
+				t.Fatalf("Failed to parse package %s: %v", packagePath, err)
+			}
+			if !bytes.Equal(jsonData, expected[packagePath]) {
+				t.Errorf("Round %d result for %s differs from result without cache", round, packagePath)
+			}
+		}
+	}
+	if stats := p.CacheStats(); stats.Hits != int64(len(concurrencyTestPackages)) {
+		t.Errorf("Expected %d cache hits, got %+v", len(concurrencyTestPackages), stats)
+	}
+}
+
+// rejectRule правило фильтрации, отбрасывающее все интерфейсы
+type rejectRule struct{}
+
+func (rejectRule) ShouldInclude(models.Interface) (shouldInclude bool) {
+	return
+}
+
+// TestParsePackageCacheCustomRules проверяет, что парсер с собственными правилами, этапами, хуками,
+// middleware или парсером аннотаций без ключа кэша не берет чужие записи кэша
+func TestParsePackageCacheCustomRules(t *testing.T) {
+
+	ctx := context.Background()
+	packageDir := writeCacheTestModule(t)
+	cacheDir := t.TempDir()
+	if _, err := NewParser(WithCache(cacheDir)).ParsePackage(ctx, packageDir); err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+	for name, p := range map[string]*Parser{
+		"filter rules":                        NewParser(WithCache(cacheDir), WithFilterRules(rejectRule{})),
+		"annotation parser without cache key": NewParser(WithCache(cacheDir), WithAnnotationParser(logParser{})),
+		"custom stage":                        NewParser(WithCache(cacheDir), WithStageAfter(pipeline.StageNameFilter, pipeline.Named(pipeline.StageNameFilter+"-reject", pipeline.NewStageFilter(rejectRule{})))),
+		"hook": NewParser(WithCache(cacheDir), WithHooks(pipeline.Hooks{AfterStage: func(ctx context.Context, event pipeline.StageEvent) (err error) {
+			if event.Stage == pipeline.StageNameAST {
+				err = pipeline.ErrSkipRemaining
+			}
+			return
+		}})),
+		"middleware": NewParser(WithCache(cacheDir), WithMiddleware(func(name string, next pipeline.Stage) (stage pipeline.Stage) {
+			stage = next
+			if name == pipeline.StageNameFilter {
+				stage = pipeline.NewStageFilter(rejectRule{})
+			}
+			return
+		})),
+	} {
+		result, err := p.ParsePackage(ctx, packageDir)
+		if err != nil {
+			t.Fatalf("%s: failed to parse package: %v", name, err)
+		}
+		if len(result.Interfaces) != 0 {
+			t.Errorf("%s: expected interfaces to be filtered out, got %d", name, len(result.Interfaces))
+		}
+		if stats := p.CacheStats(); stats.Hits != 0 || stats.Misses != 0 {
+			t.Errorf("%s: expected cache to be bypassed, got %+v", name, stats)
+		}
+	}
+}
+
+// keyedParser собственный парсер аннотаций с ключом кэша
+type keyedParser struct {
+	prefix string
+}
+
+func (p keyedParser) Parse(ctx context.Context, text string) (annotations models.Annotations, err error) {
+
+	annotations, err = models.NewAnnotationParser(p.prefix).Parse(ctx, text)
+	return
+}
+
+func (p keyedParser) CacheKey() (key string) {
+
+	key = p.prefix
+	return
+}
+
+// TestParsePackageCacheParserKey проверяет, что экземпляры одного типа парсера с разными настройками
+// не используют записи друг друга
+func TestParsePackageCacheParserKey(t *testing.T) {
+
+	ctx := context.Background()
+	packageDir := writeCacheTestModule(t)
+	cacheDir := t.TempDir()
+	if _, err := NewParser(WithCache(cacheDir), WithAnnotationParser(keyedParser{prefix: "@asti"})).ParsePackage(ctx, packageDir); err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+	p := NewParser(WithCache(cacheDir), WithAnnotationParser(keyedParser{prefix: "@other"}))
+	result, err := p.ParsePackage(ctx, packageDir)
+	if err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+	if stats := p.CacheStats(); stats.Hits != 0 || stats.Misses != 1 || len(result.Interfaces) != 0 {
+		t.Errorf("Expected a miss and no @asti interfaces, got %+v and %d interfaces", stats, len(result.Interfaces))
+	}
+}
+
+// TestParsePackageCacheTypeChecking проверяет, что с проверкой типов кэш не возвращает типы,
+// устаревшие после изменения другого пакета
+func TestParsePackageCacheTypeChecking(t *testing.T) {
+
+	ctx := context.Background()
+	root := t.TempDir()
+	for _, dir := range []string{"service", "model"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module github.com/test/cache\n\ngo 1.24\n")
+	writeTestFile(t, filepath.Join(root, "model", "model.go"), "package model\n\ntype ID = string\n")
+	writeTestFile(t, filepath.Join(root, "service", "service.go"), `package service
+
+import (
+	"context"
+
+	"github.com/test/cache/model"
+)
+
+// @asti name=UserService
+type UserService interface {
+	// @asti method=Get
+	Get(ctx context.Context, id model.ID) (err error)
+}
+`)
+
+	p := NewParser(WithCache(t.TempDir()), WithTypeChecking())
+	packageDir := filepath.Join(root, "service")
+	for _, expected := range []string{"string", "int64"} {
+		if expected == "int64" {
+			writeTestFile(t, filepath.Join(root, "model", "model.go"), "package model\n\ntype ID = int64\n")
+		}
+		result, err := p.ParsePackage(ctx, packageDir)
+		if err != nil {
+			t.Fatalf("Failed to parse package: %v", err)
+		}
+		if actual := result.Interfaces[0].Methods[0].Parameters[1].Type; actual != expected {
+			t.Errorf("Expected id type %s, got %s", expected, actual)
+		}
+	}
+	if stats := p.CacheStats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("Expected cache to be bypassed with type checking, got %+v", stats)
+	}
+}
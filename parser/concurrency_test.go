@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+var concurrencyTestPackages = []string{
+	"../examples/sample",
+	"../examples/complex/advanced_annotations",
+	"../examples/complex/complex_imports",
+	"../examples/complex/corner_cases",
+	"../examples/complex/cyclic_structures",
+	"../examples/complex/edge_cases",
+	"../examples/complex/generic_types",
+	"../examples/complex/nested_types",
+}
+
+// expectedPackagesJSON возвращает эталонный JSON пакетов, разобранных отдельными парсерами
+func expectedPackagesJSON(t *testing.T) (expected map[string][]byte) {
+
+	t.Helper()
+	expected = make(map[string][]byte, len(concurrencyTestPackages))
+	for _, packagePath := range concurrencyTestPackages {
+		jsonData, err := NewParser().ParsePackageToJSON(context.Background(), packagePath)
+		if err != nil {
+			t.Fatalf("Failed to parse package %s: %v", packagePath, err)
+		}
+		expected[packagePath] = jsonData
+	}
+	return
+}
+
+// TestParserSequentialReuse проверяет, что состояние разбора не переносится между вызовами
+func TestParserSequentialReuse(t *testing.T) {
+
+	expected := expectedPackagesJSON(t)
+	p := NewParser()
+	for _, packagePath := range concurrencyTestPackages {
+		jsonData, err := p.ParsePackageToJSON(context.Background(), packagePath)
+		if err != nil {
+			t.Fatalf("Failed to parse package %s: %v", packagePath, err)
+		}
+		if !bytes.Equal(jsonData, expected[packagePath]) {
+			t.Errorf("Result for %s differs from result of a fresh parser", packagePath)
+		}
+	}
+}
+
+// TestParserConcurrentParsePackage проверяет одновременные вызовы ParsePackage на одном парсере
+func TestParserConcurrentParsePackage(t *testing.T) {
+
+	expected := expectedPackagesJSON(t)
+	p := NewParser()
+
+	var wg sync.WaitGroup
+	for round := 0; round < 4; round++ {
+		for _, packagePath := range concurrencyTestPackages {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				jsonData, err := p.ParsePackageToJSON(context.Background(), packagePath)
+				if err != nil {
+					t.Errorf("Failed to parse package %s: %v", packagePath, err)
+					return
+				}
+				if !bytes.Equal(jsonData, expected[packagePath]) {
+					t.Errorf("Concurrent result for %s differs from result of a fresh parser", packagePath)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// TestParsePackages проверяет параллельный разбор с ограниченным числом воркеров
+func TestParsePackages(t *testing.T) {
+
+	expected := expectedPackagesJSON(t)
+	p := NewParser(WithConcurrency(3))
+
+	packages, err := p.ParsePackages(context.Background(), concurrencyTestPackages...)
+	if err != nil {
+		t.Fatalf("Failed to parse packages: %v", err)
+	}
+	if len(packages) != len(concurrencyTestPackages) {
+		t.Fatalf("Expected %d packages, got %d", len(concurrencyTestPackages), len(packages))
+	}
+	for i, pkg := range packages {
+		packagePath := concurrencyTestPackages[i]
+		jsonData, err := p.ToJSON(pkg)
+		if err != nil {
+			t.Fatalf("Failed to serialize package %s: %v", packagePath, err)
+		}
+		if !bytes.Equal(jsonData, expected[packagePath]) {
+			t.Errorf("Result for %s is out of order or differs from result of a fresh parser", packagePath)
+		}
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	if _, err = p.ParsePackages(context.Background(), concurrencyTestPackages[0], missing); err == nil {
+		t.Error("Expected error for missing package, got nil")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = p.ParsePackages(ctx, concurrencyTestPackages...); err == nil {
+		t.Error("Expected error for cancelled context, got nil")
+	}
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+const decodeServiceFile = `package service
+
+import "context"
+
+// @asti name=OrderService timeout=30s retry=true tags=orders,billing
+type OrderService interface {
+	// @asti method=Create attempts=3
+	Create(ctx context.Context) (err error)
+	// @asti method=Cancel attempts=many unknown=1
+	Cancel(ctx context.Context) (err error)
+}
+`
+
+// serviceConfig конфигурация генератора из аннотаций интерфейса
+type serviceConfig struct {
+	Name    string        `asti:"name,required"`
+	Timeout time.Duration `asti:"timeout"`
+	Retry   bool
+	Tags    []string `asti:"tags"`
+	Version string   `asti:"version,default=v1"`
+	Limit   *int     `asti:"limit,default=100"`
+}
+
+// methodConfig конфигурация метода из аннотаций
+type methodConfig struct {
+	Method   string `asti:"method,required"`
+	Attempts int    `asti:"attempts,default=1"`
+	Path     string `asti:"path,required"`
+}
+
+// TestDecodeAnnotations проверяет декодирование аннотаций в структуры
+func TestDecodeAnnotations(t *testing.T) {
+
+	result := parseService(t, decodeServiceFile)
+	if len(result.Interfaces) != 1 || len(result.Interfaces[0].Methods) != 2 {
+		t.Fatalf("Expected 1 interface with 2 methods, got %+v", result.Interfaces)
+	}
+	iface := result.Interfaces[0]
+	limit := 100
+
+	for _, test := range []struct {
+		name     string
+		decode   func(target any) (err error)
+		target   any
+		expected any
+		check    func(err error) (ok bool)
+	}{
+		{
+			name:     "interface",
+			decode:   iface.DecodeAnnotations,
+			target:   &serviceConfig{},
+			expected: &serviceConfig{Name: "OrderService", Timeout: 30 * time.Second, Retry: true, Tags: []string{"orders", "billing"}, Version: "v1", Limit: &limit},
+			check:    func(err error) (ok bool) { return err == nil },
+		},
+		{
+			name:     "missing required",
+			decode:   iface.Methods[0].DecodeAnnotations,
+			target:   &methodConfig{},
+			expected: &methodConfig{Method: "Create", Attempts: 3},
+			check: func(err error) (ok bool) {
+				return errors.Is(err, models.ErrRequiredAnnotation) && strings.Contains(err.Error(), "path")
+			},
+		},
+		{
+			name:     "conversion and unknown",
+			decode:   iface.Methods[1].DecodeAnnotations,
+			target:   &methodConfig{},
+			expected: &methodConfig{Method: "Cancel"},
+			check: func(err error) (ok bool) {
+				var valueErr *models.ValueError
+				return errors.As(err, &valueErr) && valueErr.Key == "attempts" && valueErr.Position.Line == 9 &&
+					errors.Is(err, models.ErrUnknownAnnotation) && strings.Contains(err.Error(), "service.go:9:")
+			},
+		},
+		{
+			name:     "non-pointer target",
+			decode:   iface.Annotations.Decode,
+			target:   serviceConfig{},
+			expected: serviceConfig{},
+			check:    func(err error) (ok bool) { return err != nil },
+		},
+	} {
+		err := test.decode(test.target)
+		if !test.check(err) {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		}
+		if !reflect.DeepEqual(test.target, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expected, test.target)
+		}
+	}
+}
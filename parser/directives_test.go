@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+)
+
+const directivesServiceFile = `package service
+
+import "context"
+
+// UserService управляет пользователями
+//
+//asti:name UserService
+//asti:deprecated
+//http:prefix /api
+type UserService interface {
+	// Get возвращает пользователя
+	//asti:http method=GET path=/users/{id}
+	//asti:tags [users, read]
+	//asti:description Returns a user by id
+	// asti:ignored value
+	Get(ctx context.Context, id string) (err error)
+}
+
+type User struct {
+	//asti:column user_id
+	ID string
+}
+`
+
+// TestDirectiveAnnotations проверяет аннотации в форме директив //asti:key value
+func TestDirectiveAnnotations(t *testing.T) {
+
+	result := parseService(t, directivesServiceFile, WithAnnotationNamespaces("http"))
+	if len(result.Interfaces) != 1 {
+		t.Fatalf("Expected 1 interface, got %d", len(result.Interfaces))
+	}
+	iface := result.Interfaces[0]
+	annotations := iface.Methods[0].Annotations
+	httpValue, _ := annotations.Lookup("http")
+	fields, err := httpValue.Object()
+	if err != nil || len(fields) != 2 {
+		t.Fatalf("Expected http object, got %+v (%v)", httpValue, err)
+	}
+	tags, _ := annotations.List("tags")
+	position := httpValue.Fields[1].Value.Position
+
+	checkExpectations(t, []expectation{
+		{name: "interface name", actual: iface.Annotations.Get("name"), expected: "UserService"},
+		{name: "interface flag", actual: fmt.Sprint(iface.Annotations.Has("deprecated")), expected: "true"},
+		{name: "interface http prefix", actual: iface.Namespaces.Get("http", "prefix"), expected: "/api"},
+		{name: "method keys", actual: fmt.Sprint(annotations.Keys()), expected: "[http tags description]"},
+		{name: "http method", actual: fields.Get("method"), expected: "GET"},
+		{name: "http path", actual: fields.Get("path"), expected: "/users/{id}"},
+		{name: "http path position", actual: fmt.Sprintf("%d:%d", position.Line, position.Column), expected: "12:30"},
+		{name: "tags", actual: fmt.Sprintf("%q", tags), expected: `["users" "read"]`},
+		{name: "description", actual: annotations.Get("description"), expected: "Returns a user by id"},
+		{name: "field column", actual: result.Types["service.User"].Fields[0].Annotations.Get("column"), expected: "user_id"},
+	})
+}
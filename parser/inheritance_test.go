@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+	"github.com/seniorGolang/asti/parser/pipeline"
+)
+
+const inheritanceServiceFile = `// @asti timeout=30s tags=base owner=platform
+package service
+
+import "context"
+
+// @asti name=UserService timeout=10s tags=users
+type UserService interface {
+	// @asti method=Get tags=read
+	Get(ctx context.Context) (err error)
+	// @asti method=List timeout=1m
+	List(ctx context.Context) (err error)
+}
+
+// @asti table=users
+type User struct {
+	// @asti column=id
+	ID string
+}
+`
+
+// TestAnnotationInheritance проверяет действующие аннотации и их происхождение
+func TestAnnotationInheritance(t *testing.T) {
+
+	option := WithAnnotationInheritance(models.InheritanceRules{
+		Keys: map[string]models.InheritMode{"tags": models.InheritAppend, "name": models.InheritNone, "method": models.InheritNone},
+	})
+	if stages := NewParser(option).Stages(); stages[len(stages)-2] != pipeline.StageNameInheritance {
+		t.Errorf("Expected inheritance stage before serialization, got %v", stages)
+	}
+	result := parseService(t, inheritanceServiceFile, option)
+	iface := result.Interfaces[0]
+	get, list := iface.Methods[0], iface.Methods[1]
+	field := result.Types["service.User"].Fields[0]
+	tags, _ := get.Effective.List("tags")
+	listSource, _ := list.Effective.Source("timeout")
+	fieldSource, _ := field.Effective.Source("table")
+	_, restored := roundtrip(t, result)
+	restoredSource, _ := restored.Interfaces[0].Methods[0].Effective.Source("owner")
+
+	checkExpectations(t, []expectation{
+		{name: "interface effective keys", actual: fmt.Sprint(iface.Effective.Keys()), expected: "[tags owner name timeout]"},
+		{name: "own annotations of Get", actual: fmt.Sprint(get.Annotations.Keys()), expected: "[method tags]"},
+		{name: "appended tags of Get", actual: fmt.Sprint(tags), expected: "[base users read]"},
+		{name: "name of Get", actual: fmt.Sprint(get.Effective.Has("name")), expected: "false"},
+		{name: "timeout of Get", actual: get.Effective.Get("timeout"), expected: "10s"},
+		{name: "owner of Get", actual: get.Effective.Get("owner"), expected: "platform"},
+		{name: "timeout of List", actual: list.Effective.Get("timeout"), expected: "1m"},
+		{name: "timeout source of List", actual: string(listSource), expected: string(models.TargetMethod)},
+		{name: "table source of field", actual: string(fieldSource), expected: string(models.TargetType)},
+		{name: "column of field", actual: field.Effective.Get("column"), expected: "id"},
+		{name: "owner source after roundtrip", actual: string(restoredSource), expected: string(models.TargetPackage)},
+	})
+	expected := models.Provenance{
+		"tags":    {models.TargetPackage, models.TargetInterface, models.TargetMethod},
+		"owner":   {models.TargetPackage},
+		"timeout": {models.TargetInterface},
+		"method":  {models.TargetMethod},
+	}
+	if !reflect.DeepEqual(get.Provenance, expected) {
+		t.Errorf("Unexpected provenance %v", get.Provenance)
+	}
+}
+
+// TestAnnotationInheritanceUnknownMode проверяет ошибку конфигурации для неизвестного режима наследования
+func TestAnnotationInheritanceUnknownMode(t *testing.T) {
+
+	p := NewParser(WithAnnotationInheritance(models.InheritanceRules{Keys: map[string]models.InheritMode{"tags": "merge"}}))
+	_, err := p.ParseSources(context.Background(), serviceSources(inheritanceServiceFile))
+	if err == nil || !strings.Contains(err.Error(), `tags: unknown mode "merge"`) {
+		t.Errorf("Expected unknown mode error, got %v", err)
+	}
+}
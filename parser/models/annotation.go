@@ -1,135 +1,635 @@
 package models
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
-type Annotations map[string]string
+// ErrAnnotationNotFound аннотация с запрошенным ключом отсутствует
+var ErrAnnotationNotFound = errors.New("annotation not found")
 
-type AnnotationParser interface {
-	Parse(ctx context.Context, text string) (annotations Annotations, err error)
+// Annotation пара ключ-значение аннотации. Source — уровень, с которого значение получено
+// в действующих аннотациях (Effective); у собственных аннотаций элемента пуст.
+type Annotation struct {
+	Key    string
+	Value  Value
+	Source Target
 }
 
-type DefaultAnnotationParser struct {
-	prefix string
+// Annotations аннотации элемента в порядке объявления. Ключ может повторяться
+// (@asti header=X-Request-ID @asti header=X-Trace-ID): одиночные методы доступа
+// возвращают последнее значение, Values — все значения ключа.
+// В JSON сериализуются объектом "ключ": {"raw", "kind", "position"}, повторяющийся ключ — массивом
+// значений; элементы массивов и поля объектов записываются в "items" и "fields".
+type Annotations []Annotation
+
+// Lookup возвращает последнее значение аннотации по ключу
+func (a Annotations) Lookup(key string) (value Value, found bool) {
+
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i].Key == key {
+			value, found = a[i].Value, true
+			return
+		}
+	}
+	return
 }
 
-func NewAnnotationParser(prefix string) (parser *DefaultAnnotationParser) {
+// Values возвращает все значения аннотации по ключу в порядке объявления
+func (a Annotations) Values(key string) (values []Value) {
 
-	if prefix == "" {
-		prefix = "@asti"
+	for _, annotation := range a {
+		if annotation.Key == key {
+			values = append(values, annotation.Value)
+		}
 	}
+	return
+}
+
+// Get возвращает текст последнего значения аннотации без кавычек или пустую строку
+func (a Annotations) Get(key string) (text string) {
 
-	parser = &DefaultAnnotationParser{prefix: prefix}
+	if value, found := a.Lookup(key); found {
+		text = value.String()
+	}
 	return
 }
 
-func (p *DefaultAnnotationParser) GetPrefix() (prefix string) {
+// GetAll возвращает тексты всех значений аннотации по ключу
+func (a Annotations) GetAll(key string) (texts []string) {
 
-	prefix = p.prefix
+	for _, value := range a.Values(key) {
+		texts = append(texts, value.String())
+	}
 	return
 }
 
-// Parse парсит аннотации из текста комментария
-func (p *DefaultAnnotationParser) Parse(_ context.Context, text string) (annotations Annotations, err error) {
+// Has сообщает, есть ли аннотация с ключом key
+func (a Annotations) Has(key string) (found bool) {
 
-	annotations = make(Annotations)
+	_, found = a.Lookup(key)
+	return
+}
 
-	text = strings.TrimSpace(text)
+// Keys возвращает различные ключи аннотаций в порядке первого объявления
+func (a Annotations) Keys() (keys []string) {
 
-	if strings.HasPrefix(text, "//") {
-		text = strings.TrimSpace(strings.TrimPrefix(text, "//"))
+	seen := make(map[string]bool, len(a))
+	for _, annotation := range a {
+		if !seen[annotation.Key] {
+			seen[annotation.Key] = true
+			keys = append(keys, annotation.Key)
+		}
 	}
+	return
+}
+
+// Add добавляет значение аннотации, сохраняя существующие значения с тем же ключом
+func (a *Annotations) Add(key string, value Value) {
+
+	*a = append(*a, Annotation{Key: key, Value: value})
+}
 
-	if !strings.HasPrefix(text, p.prefix) {
+// Set задает единственное значение аннотации, заменяя все значения с тем же ключом
+func (a *Annotations) Set(key string, value Value) {
+
+	annotations := make(Annotations, 0, len(*a)+1)
+	replaced := false
+	for _, annotation := range *a {
+		if annotation.Key != key {
+			annotations = append(annotations, annotation)
+			continue
+		}
+		if !replaced {
+			annotations = append(annotations, Annotation{Key: key, Value: value})
+			replaced = true
+		}
+	}
+	if !replaced {
+		annotations = append(annotations, Annotation{Key: key, Value: value})
+	}
+	*a = annotations
+}
+
+// Merge добавляет аннотации other после существующих, сохраняя повторяющиеся ключи
+func (a *Annotations) Merge(other Annotations) {
+
+	*a = append(*a, other...)
+}
+
+// WithBase возвращает копию аннотаций с позициями относительно base. Парсер аннотаций
+// отсчитывает позиции от начала текста комментария (строка 1), base — позиция комментария.
+// Значениям без позиции присваивается base.
+func (a Annotations) WithBase(base Position) (annotations Annotations) {
+
+	if a == nil {
 		return
 	}
+	annotations = make(Annotations, len(a))
+	for i, annotation := range a {
+		annotation.Value = annotation.Value.withBase(base)
+		annotations[i] = annotation
+	}
+	return
+}
+
+// withBase возвращает копию значения с позициями, включая вложенные, относительно base
+func (v Value) withBase(base Position) (value Value) {
+
+	value = v
+	value.Position = rebase(base, v.Position)
+	if v.Items != nil {
+		value.Items = make([]Value, len(v.Items))
+		for i, item := range v.Items {
+			value.Items[i] = item.withBase(base)
+		}
+	}
+	value.Fields = v.Fields.WithBase(base)
+	return
+}
 
-	content := strings.TrimSpace(strings.TrimPrefix(text, p.prefix))
+// value возвращает значение аннотации или ошибку ErrAnnotationNotFound
+func (a Annotations) value(key string) (value Value, err error) {
 
-	var currentKey string
-	var currentValue strings.Builder
-	inQuotes := false
+	var found bool
+	if value, found = a.Lookup(key); !found {
+		err = fmt.Errorf("%w: %s", ErrAnnotationNotFound, key)
+	}
+	return
+}
 
-	tokens := p.tokenize(content)
+// withKey дополняет ошибку преобразования ключом аннотации
+func withKey(key string, err error) (keyed error) {
 
-	for _, token := range tokens {
-		switch {
-		case strings.Contains(token, "=") && !inQuotes:
-			if currentKey != "" {
-				annotations[currentKey] = strings.TrimSpace(currentValue.String())
-				currentValue.Reset()
+	var valueErr *ValueError
+	if errors.As(err, &valueErr) {
+		valueErr.Key = key
+	}
+	keyed = err
+	return
+}
+
+// Int возвращает значение аннотации key как целое число
+func (a Annotations) Int(key string) (value int, err error) {
+
+	var annotation Value
+	if annotation, err = a.value(key); err != nil {
+		return
+	}
+	value, err = annotation.Int()
+	err = withKey(key, err)
+	return
+}
+
+// Float возвращает значение аннотации key как число с плавающей точкой
+func (a Annotations) Float(key string) (value float64, err error) {
+
+	var annotation Value
+	if annotation, err = a.value(key); err != nil {
+		return
+	}
+	value, err = annotation.Float()
+	err = withKey(key, err)
+	return
+}
+
+// Bool возвращает значение аннотации key как булево
+func (a Annotations) Bool(key string) (value bool, err error) {
+
+	var annotation Value
+	if annotation, err = a.value(key); err != nil {
+		return
+	}
+	value, err = annotation.Bool()
+	err = withKey(key, err)
+	return
+}
+
+// Duration возвращает значение аннотации key как длительность
+func (a Annotations) Duration(key string) (value time.Duration, err error) {
+
+	var annotation Value
+	if annotation, err = a.value(key); err != nil {
+		return
+	}
+	value, err = annotation.Duration()
+	err = withKey(key, err)
+	return
+}
+
+// List возвращает элементы значения аннотации key: элементы массива или текст, разделенный запятыми.
+// Элементы всех значений повторяющегося ключа объединяются, поэтому params=[id,name]
+// и params=id params=name дают одинаковый список.
+func (a Annotations) List(key string) (values []string, err error) {
+
+	if _, err = a.value(key); err != nil {
+		return
+	}
+	for _, annotation := range a.Values(key) {
+		var items []string
+		if items, err = annotation.List(); err != nil {
+			err = withKey(key, err)
+			values = nil
+			return
+		}
+		values = append(values, items...)
+	}
+	return
+}
+
+// MarshalJSON сериализует аннотации объектом "ключ": значение в порядке первого объявления ключей;
+// значение записывается объектом с текстом, видом и позицией, значения повторяющегося ключа — массивом
+func (a Annotations) MarshalJSON() (data []byte, err error) {
+
+	if a == nil {
+		data = []byte("null")
+		return
+	}
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range a.Keys() {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		var keyJSON, valueJSON []byte
+		if keyJSON, err = json.Marshal(key); err != nil {
+			return
+		}
+		if values := a.Values(key); len(values) == 1 {
+			valueJSON, err = values[0].marshalJSON()
+		} else {
+			items := make([]json.RawMessage, len(values))
+			for j, value := range values {
+				if items[j], err = value.marshalJSON(); err != nil {
+					return
+				}
+			}
+			valueJSON, err = json.Marshal(items)
+		}
+		if err != nil {
+			return
+		}
+		buffer.Write(keyJSON)
+		buffer.WriteByte(':')
+		buffer.Write(valueJSON)
+	}
+	buffer.WriteByte('}')
+	data = buffer.Bytes()
+	return
+}
+
+// valueJSON запись значения в JSON: исходный текст, вид, позиция и элементы массива или поля объекта.
+// Массив JSON на месте значения аннотации означает повторяющийся ключ, поэтому значение всегда объект.
+type valueJSON struct {
+	Raw      string            `json:"raw"`
+	Kind     ValueKind         `json:"kind"`
+	Position *Position         `json:"position,omitempty"`
+	Items    []json.RawMessage `json:"items,omitempty"`
+	Fields   json.RawMessage   `json:"fields,omitempty"`
+}
+
+// marshalJSON сериализует значение объектом {"raw", "kind", "position", "items"/"fields"}
+func (v Value) marshalJSON() (data []byte, err error) {
+
+	record := valueJSON{Raw: v.Raw, Kind: v.Kind}
+	if v.Position != (Position{}) {
+		position := v.Position
+		record.Position = &position
+	}
+	switch v.Kind {
+	case KindArray:
+		record.Items = make([]json.RawMessage, len(v.Items))
+		for i, item := range v.Items {
+			if record.Items[i], err = item.marshalJSON(); err != nil {
+				return
 			}
-			if key, value, found := strings.Cut(token, "="); found {
-				currentKey = strings.TrimSpace(key)
-				value = strings.TrimSpace(value)
-
-				if strings.HasPrefix(value, `"`) {
-					if strings.HasSuffix(value, `"`) {
-						annotations[currentKey] = strings.Trim(value, `"`)
-						currentKey = ""
-					} else {
-						currentValue.WriteString(value[1:])
-						inQuotes = true
-					}
-				} else {
-					annotations[currentKey] = value
-					currentKey = ""
+		}
+	case KindObject:
+		if record.Fields, err = v.Fields.MarshalJSON(); err != nil {
+			return
+		}
+	}
+	data, err = json.Marshal(record)
+	return
+}
+
+// valueFromJSON восстанавливает значение из объекта {"raw", "kind", ...}. Строка или число JSON
+// принимаются как краткая запись значения без позиции, вид определяется по тексту.
+func valueFromJSON(data json.RawMessage) (value Value, err error) {
+
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, []byte(`"`)):
+		var text string
+		if err = json.Unmarshal(data, &text); err == nil {
+			value = Value{Raw: text, Kind: inferKind(text)}
+		}
+	case bytes.HasPrefix(data, []byte("{")):
+		var record valueJSON
+		if err = json.Unmarshal(data, &record); err != nil {
+			return
+		}
+		value = Value{Raw: record.Raw, Kind: record.Kind}
+		if record.Position != nil {
+			value.Position = *record.Position
+		}
+		switch record.Kind {
+		case KindArray:
+			for _, item := range record.Items {
+				var itemValue Value
+				if itemValue, err = valueFromJSON(item); err != nil {
+					return
 				}
+				value.Items = append(value.Items, itemValue)
 			}
-		case inQuotes:
-			if strings.HasSuffix(token, `"`) {
-				currentValue.WriteString(" " + strings.TrimSuffix(token, `"`))
-				annotations[currentKey] = strings.TrimSpace(currentValue.String())
-				currentKey = ""
-				currentValue.Reset()
-				inQuotes = false
-			} else {
-				currentValue.WriteString(" " + token)
+		case KindObject:
+			if len(record.Fields) > 0 {
+				err = value.Fields.UnmarshalJSON(record.Fields)
 			}
-		case currentKey != "":
-			currentValue.WriteString(" " + token)
+		case "":
+			value.Kind = inferKind(record.Raw)
+		case KindString, KindInt, KindFloat, KindBool, KindDuration, KindList:
 		default:
-			// Обработка короткой записи булевых значений (только ключ без значения)
-			// Аннотация вида @asti key интерпретируется как @asti key=true
-			annotations[strings.TrimSpace(token)] = "true"
+			err = fmt.Errorf("unknown value kind %q", record.Kind)
 		}
+	case bytes.HasPrefix(data, []byte("[")):
+		err = fmt.Errorf("unexpected array in annotation value")
+	default:
+		value = NewValue(string(data))
 	}
+	return
+}
 
-	if currentKey != "" {
-		annotations[currentKey] = strings.TrimSpace(currentValue.String())
+// UnmarshalJSON восстанавливает аннотации из объекта, сохраняя порядок ключей и повторяющиеся значения.
+// Массив JSON на месте значения восстанавливается как значения повторяющегося ключа.
+func (a *Annotations) UnmarshalJSON(data []byte) (err error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var token json.Token
+	if token, err = decoder.Token(); err != nil {
+		return
+	}
+	if token == nil {
+		*a = nil
+		return
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		err = fmt.Errorf("annotations must be a JSON object")
+		return
+	}
+	annotations := Annotations{}
+	for decoder.More() {
+		if token, err = decoder.Token(); err != nil {
+			return
+		}
+		key, _ := token.(string)
+		var raw json.RawMessage
+		if err = decoder.Decode(&raw); err != nil {
+			err = fmt.Errorf("annotation %s: %w", key, err)
+			return
+		}
+		items := []json.RawMessage{raw}
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			if err = json.Unmarshal(raw, &items); err != nil {
+				err = fmt.Errorf("annotation %s: %w", key, err)
+				return
+			}
+		}
+		for _, item := range items {
+			var value Value
+			if value, err = valueFromJSON(item); err != nil {
+				err = fmt.Errorf("annotation %s: %w", key, err)
+				return
+			}
+			annotations.Add(key, value)
+		}
 	}
+	*a = annotations
+	return
+}
+
+type AnnotationParser interface {
+	Parse(ctx context.Context, text string) (annotations Annotations, err error)
+}
+
+// AnnotationMatcher может реализовываться парсером аннотаций, чтобы отбирать строки комментариев
+// с аннотациями без разбора. Для парсера без Match строка комментария считается аннотацией,
+// если Parse возвращает для нее аннотации или ошибку.
+type AnnotationMatcher interface {
+	Match(commentText string) (matched bool)
+}
+
+// AnnotationCacheKeyer может реализовываться парсером аннотаций, чтобы кэш результатов различал
+// по-разному настроенные экземпляры одного типа: CacheKey описывает настройки, влияющие на разбор.
+// С парсером без CacheKey кэш результатов не используется.
+type AnnotationCacheKeyer interface {
+	CacheKey() (key string)
+}
+
+type DefaultAnnotationParser struct {
+	prefix    string
+	directive string
+}
 
+// NewAnnotationParser создает парсер аннотаций с префиксом prefix. Кроме записи "// @asti key=value"
+// парсер понимает директивы "//asti:key value" с именем префикса без @, см. WithDirective.
+func NewAnnotationParser(prefix string, options ...AnnotationParserOption) (parser *DefaultAnnotationParser) {
+
+	if prefix == "" {
+		prefix = "@asti"
+	}
+
+	parser = &DefaultAnnotationParser{prefix: prefix, directive: defaultDirective(prefix)}
+	for _, apply := range options {
+		apply(parser)
+	}
 	return
 }
 
-// tokenize разбивает строку аннотации на токены
-func (p *DefaultAnnotationParser) tokenize(content string) (tokens []string) {
+func (p *DefaultAnnotationParser) GetPrefix() (prefix string) {
 
-	var current strings.Builder
-	inQuotes := false
+	prefix = p.prefix
+	return
+}
 
-	for i := 0; i < len(content); i++ {
-		char := content[i]
+// CacheKey описывает настройки парсера для ключа кэша: префикс и имя директив
+func (p *DefaultAnnotationParser) CacheKey() (key string) {
+
+	key = p.prefix + ":" + p.directive
+	return
+}
 
+// Match сообщает, начинается ли комментарий // или /* */ с префикса аннотаций или является директивой
+func (p *DefaultAnnotationParser) Match(commentText string) (matched bool) {
+
+	commentText = strings.TrimSpace(commentText)
+	if p.isDirective(commentText) {
+		matched = true
+		return
+	}
+	switch {
+	case strings.HasPrefix(commentText, "//"):
+		commentText = strings.TrimPrefix(commentText, "//")
+	case strings.HasPrefix(commentText, "/*"):
+		commentText = strings.TrimSuffix(strings.TrimPrefix(commentText, "/*"), "*/")
+		// Строки блочного комментария могут начинаться с *
+		commentText = strings.TrimLeft(commentText, " \t\r\n*")
+	default:
+		return
+	}
+	matched = hasPrefixWord(strings.TrimSpace(commentText), p.prefix)
+	return
+}
+
+// hasPrefixWord сообщает, начинается ли text с prefix, за которым следует пробельный символ или конец текста:
+// префикс @asti не совпадает с @astix
+func hasPrefixWord(text string, prefix string) (ok bool) {
+
+	if !strings.HasPrefix(text, prefix) {
+		return
+	}
+	next, _ := utf8.DecodeRuneInString(text[len(prefix):])
+	ok = len(text) == len(prefix) || unicode.IsSpace(next)
+	return
+}
+
+// annotationToken токен аннотации и его смещение в тексте аннотации
+type annotationToken struct {
+	text   string
+	offset int
+}
+
+// commentSource текст аннотации, собранный из строк комментария без маркеров комментария,
+// с соответствием смещений позициям в исходном тексте
+type commentSource struct {
+	content    string
+	lineStarts []int
+	columns    []int
+}
+
+// newCommentSource убирает из строк text маркеры //, /* */ и продолжения строк \.
+// Строки разделяются переводом строки, поэтому значение в кавычках может занимать несколько строк.
+func newCommentSource(text string) (source commentSource) {
+
+	var builder strings.Builder
+	lines := strings.Split(text, "\n")
+	block := strings.HasPrefix(strings.TrimLeft(text, " \t\n"), "/*")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		column := len(line) - len(strings.TrimLeft(line, " \t"))
+		marker := true
 		switch {
-		case char == '"':
-			inQuotes = !inQuotes
-			current.WriteByte(char)
-		case char == ' ' && !inQuotes:
-			if current.Len() > 0 {
-				tokens = append(tokens, current.String())
-				current.Reset()
-			}
+		case strings.HasPrefix(line[column:], "//") && !block:
+			column += 2
+		case strings.HasPrefix(line[column:], "/*") && block && i == 0:
+			column += 2
+		case strings.HasPrefix(line[column:], "*") && !strings.HasPrefix(line[column:], "*/") && block && i > 0:
+			column++
 		default:
-			current.WriteByte(char)
+			marker = false
 		}
+		// Пробел после маркера комментария не входит в значение, продолженное с предыдущей строки
+		if marker && strings.HasPrefix(line[column:], " ") {
+			column++
+		}
+		content := line[column:]
+		if block {
+			if end := strings.Index(content, "*/"); end != -1 {
+				content = content[:end]
+			}
+		}
+		// \ продолжает строку, только если за ней есть следующая строка, иначе это часть значения: path=C:\dir\
+		if trimmed := strings.TrimRight(content, " \t"); strings.HasSuffix(trimmed, "\\") && i < len(lines)-1 {
+			content = trimmed[:len(trimmed)-1]
+		}
+		if i > 0 {
+			builder.WriteByte('\n')
+		}
+		source.lineStarts = append(source.lineStarts, builder.Len())
+		source.columns = append(source.columns, column+1)
+		builder.WriteString(content)
+	}
+	source.content = builder.String()
+	return
+}
+
+// position возвращает позицию смещения offset в content относительно начала текста
+func (s commentSource) position(offset int) (position Position) {
+
+	line := 0
+	for line+1 < len(s.lineStarts) && s.lineStarts[line+1] <= offset {
+		line++
 	}
+	position = Position{Line: line + 1, Column: s.columns[line] + offset - s.lineStarts[line]}
+	return
+}
 
-	if current.Len() > 0 {
-		tokens = append(tokens, current.String())
+// Parse парсит аннотации из текста комментария: строки //, блочного комментария /* */
+// или нескольких строк //, в том числе с продолжением \ и значениями в кавычках на нескольких строках.
+// Значение может быть массивом [a, b] или объектом {key: value, "key": [a, b]} с пробелами внутри скобок;
+// несбалансированные скобки возвращают *SyntaxError.
+// Директива "//asti:key value" разбирается функцией parseDirective.
+// Позиции значений отсчитываются от начала text: строка с единицы, колонка — смещение в байтах плюс один.
+func (p *DefaultAnnotationParser) Parse(_ context.Context, text string) (annotations Annotations, err error) {
+
+	annotations = Annotations{}
+
+	source := newCommentSource(text)
+	content := strings.TrimLeft(source.content, " \t\n")
+	offset := len(source.content) - len(content)
+	if p.isDirective(strings.TrimSpace(text)) {
+		if annotations, err = p.parseDirective(source, content[len(p.directive)+1:], offset+len(p.directive)+1); err != nil {
+			annotations = nil
+		}
+		return
+	}
+	if !hasPrefixWord(content, p.prefix) {
+		return
 	}
+	offset += len(p.prefix)
+	content = content[len(p.prefix):]
+	if errOffset, message := checkSyntax(content); errOffset != -1 {
+		err = &SyntaxError{Position: source.position(offset + errOffset), Message: message}
+		annotations = nil
+		return
+	}
+	if err = p.parseTokens(&annotations, tokenize(content), source, offset); err != nil {
+		annotations = nil
+	}
+	return
+}
+
+// parseTokens добавляет в annotations пары key=value и флаги из токенов; offset — смещение токенов в source
+func (p *DefaultAnnotationParser) parseTokens(annotations *Annotations, tokens []annotationToken, source commentSource, offset int) (err error) {
 
+	for _, token := range tokens {
+		position := source.position(offset + token.offset)
+		key, raw, found := cutKey(token.text)
+		if key == "" {
+			err = &SyntaxError{Position: position, Message: "empty annotation key"}
+			return
+		}
+		if !found {
+			// Обработка короткой записи булевых значений (только ключ без значения)
+			// Аннотация вида @asti key интерпретируется как @asti key=true
+			annotations.Add(key, Value{Raw: "true", Kind: KindBool, Position: position})
+			continue
+		}
+		valueOffset := offset + token.offset + len(token.text) - len(raw)
+		var value Value
+		if value, err = parseValue(raw, func(rawOffset int) (position Position) {
+			return source.position(valueOffset + rawOffset)
+		}); err != nil {
+			return
+		}
+		annotations.Add(key, value)
+	}
 	return
 }
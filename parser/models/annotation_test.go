@@ -0,0 +1,174 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestAnnotationsJSONRoundTrip проверяет, что JSON различает значения-массивы и повторяющиеся ключи
+// и сохраняет текст, вид и позиции значений
+func TestAnnotationsJSONRoundTrip(t *testing.T) {
+
+	parser := NewAnnotationParser("@asti")
+	for _, test := range []struct {
+		name   string
+		text   string
+		values map[string][]string
+	}{
+		{name: "single array", text: "// @asti params=[id,name]", values: map[string][]string{"params": {"[id,name]"}}},
+		{name: "empty array", text: "// @asti x=[]", values: map[string][]string{"x": {"[]"}}},
+		{name: "repeated arrays", text: "// @asti tags=[a, b] tags=[c]", values: map[string][]string{"tags": {"[a, b]", "[c]"}}},
+		{name: "repeated scalars", text: "// @asti header=X-Request-ID header=X-Trace-ID", values: map[string][]string{"header": {"X-Request-ID", "X-Trace-ID"}}},
+		{name: "object", text: "// @asti errors={404: NotFound, 409: [a, b]}", values: map[string][]string{"errors": {"{404: NotFound, 409: [a, b]}"}}},
+		{name: "quoted number", text: `// @asti code="404" name='raw'`, values: map[string][]string{"code": {`"404"`}, "name": {"'raw'"}}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			annotations, err := parser.Parse(context.Background(), test.text)
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", test.text, err)
+			}
+			data, err := json.Marshal(annotations)
+			if err != nil {
+				t.Fatalf("Failed to marshal: %v", err)
+			}
+			var restored Annotations
+			if err = json.Unmarshal(data, &restored); err != nil {
+				t.Fatalf("Failed to unmarshal %s: %v", data, err)
+			}
+			for key, raws := range test.values {
+				values := restored.Values(key)
+				if len(values) != len(raws) {
+					t.Fatalf("%s: expected %d values, got %+v from %s", key, len(raws), values, data)
+				}
+				for i, value := range values {
+					if value.Raw != raws[i] {
+						t.Errorf("%s[%d]: expected raw %s, got %s", key, i, raws[i], value.Raw)
+					}
+				}
+			}
+			if !reflect.DeepEqual(restored, annotations) {
+				t.Errorf("Expected %+v after roundtrip, got %+v", annotations, restored)
+			}
+			var restoredList, originalList []string
+			for key := range test.values {
+				restoredList, _ = restored.List(key)
+				originalList, _ = annotations.List(key)
+				if !reflect.DeepEqual(restoredList, originalList) {
+					t.Errorf("%s: expected list %v, got %v", key, originalList, restoredList)
+				}
+			}
+		})
+	}
+}
+
+// TestAnnotationsRepeated проверяет доступ к повторяющимся ключам и их позиции
+func TestAnnotationsRepeated(t *testing.T) {
+
+	annotations, err := NewAnnotationParser("@asti").Parse(context.Background(),
+		"// @asti method=Trace header=X-Request-ID\n// header=X-Trace-ID timeout=1s\n// timeout=2s")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if headers := annotations.GetAll("header"); !reflect.DeepEqual(headers, []string{"X-Request-ID", "X-Trace-ID"}) {
+		t.Errorf("Expected both headers in order, got %v", headers)
+	}
+	if timeout := annotations.Get("timeout"); timeout != "2s" {
+		t.Errorf("Expected single-value accessor to return the last timeout, got %q", timeout)
+	}
+	if keys := annotations.Keys(); !reflect.DeepEqual(keys, []string{"method", "header", "timeout"}) {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+	if values := annotations.Values("header"); len(values) != 2 || values[1].Position.Line != 2 || values[1].Position.Column != 11 {
+		t.Errorf("Expected second header at 2:11, got %+v", values)
+	}
+	annotations.Set("header", NewValue("X-Span-ID"))
+	if headers := annotations.GetAll("header"); !reflect.DeepEqual(headers, []string{"X-Span-ID"}) {
+		t.Errorf("Expected Set to replace all headers, got %v", headers)
+	}
+}
+
+// TestAnnotationsJSON проверяет формат JSON значений и ошибки восстановления
+func TestAnnotationsJSON(t *testing.T) {
+
+	annotations := Annotations{
+		{Key: "header", Value: Value{Raw: "X-Request-ID", Kind: KindString, Position: Position{File: "service.go", Line: 7, Column: 31}}},
+		{Key: "params", Value: NewValue("[id, 2]")},
+		{Key: "header", Value: NewValue("X-Trace-ID")},
+	}
+	data, err := json.Marshal(annotations)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	expected := `{"header":[{"raw":"X-Request-ID","kind":"string","position":{"file":"service.go","line":7,"column":31}},{"raw":"X-Trace-ID","kind":"string"}],` +
+		`"params":{"raw":"[id, 2]","kind":"array","items":[{"raw":"id","kind":"string"},{"raw":"2","kind":"int"}]}}`
+	if string(data) != expected {
+		t.Errorf("Expected JSON:\n%s\ngot:\n%s", expected, data)
+	}
+
+	for data, expected := range map[string]string{
+		`{"timeout": "30s", "tags": "a,b"}`:                                         "timeout=duration:30s tags=list:a,b",
+		`{"code": {"raw": "404"}}`:                                                  "code=int:404",
+		`{"code": {"raw": "\"404\"", "kind": "string"}}`:                            `code=string:"404"`,
+		`{"a": 1, "b": true}`:                                                       "a=int:1 b=bool:true",
+		`{"errors": {"raw": "{404: x}", "kind": "object", "fields": {"404": "x"}}}`: "errors=object:{404: x}",
+		`null`: "",
+	} {
+		var restored Annotations
+		if err = json.Unmarshal([]byte(data), &restored); err != nil {
+			t.Errorf("%s: unexpected error %v", data, err)
+			continue
+		}
+		var actual []string
+		for _, annotation := range restored {
+			actual = append(actual, annotation.Key+"="+string(annotation.Value.Kind)+":"+annotation.Value.Raw)
+		}
+		if strings.Join(actual, " ") != expected {
+			t.Errorf("%s: expected %s, got %s", data, expected, strings.Join(actual, " "))
+		}
+	}
+	for data, expected := range map[string]string{
+		`{"code": {"raw": "1", "kind": "number"}}`: `unknown value kind "number"`,
+		`{"tags": [["a", "b"]]}`:                   "unexpected array in annotation value",
+		`["a"]`:                                    "annotations must be a JSON object",
+	} {
+		var restored Annotations
+		if err = json.Unmarshal([]byte(data), &restored); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %s, got %v", data, expected, err)
+		}
+	}
+}
+
+// TestAnnotationParserPrefix проверяет, что префикс аннотации заканчивается пробелом или концом текста
+func TestAnnotationParserPrefix(t *testing.T) {
+
+	for _, test := range []struct {
+		prefix  string
+		text    string
+		matched bool
+		keys    []string
+	}{
+		{prefix: "@asti", text: "// @asti foo=1", matched: true, keys: []string{"foo"}},
+		{prefix: "@asti", text: "// @asti\tfoo=1", matched: true, keys: []string{"foo"}},
+		{prefix: "@asti", text: "// @asti", matched: true},
+		{prefix: "@asti", text: "// @astix foo=1"},
+		{prefix: "@log", text: "// @logger level=debug"},
+		{prefix: "@log", text: "/* @logger level=\"x */"},
+		{prefix: "@log", text: "/* @log level=debug */", matched: true, keys: []string{"level"}},
+	} {
+		parser := NewAnnotationParser(test.prefix)
+		if matched := parser.Match(test.text); matched != test.matched {
+			t.Errorf("%s %s: expected match %v, got %v", test.prefix, test.text, test.matched, matched)
+		}
+		annotations, err := parser.Parse(context.Background(), test.text)
+		if err != nil {
+			t.Errorf("%s %s: unexpected error %v", test.prefix, test.text, err)
+			continue
+		}
+		if keys := annotations.Keys(); !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("%s %s: expected keys %v, got %v", test.prefix, test.text, test.keys, keys)
+		}
+	}
+}
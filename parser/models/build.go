@@ -0,0 +1,8 @@
+package models
+
+// BuildInfo описывает ограничения сборки, с которыми был разобран пакет
+type BuildInfo struct {
+	GOOS   string   `json:"goos"`
+	GOARCH string   `json:"goarch"`
+	Tags   []string `json:"tags,omitempty"`
+}
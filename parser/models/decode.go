@@ -0,0 +1,317 @@
+package models
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnknownAnnotation аннотация не соответствует ни одному полю структуры
+	ErrUnknownAnnotation = errors.New("unknown annotation")
+	// ErrRequiredAnnotation отсутствует аннотация, отмеченная required
+	ErrRequiredAnnotation = errors.New("required annotation is missing")
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decodeField поле структуры, в которое декодируется аннотация
+type decodeField struct {
+	key          string
+	index        []int
+	required     bool
+	defaultValue *string
+}
+
+// Decode заполняет структуру, на которую указывает v, значениями аннотаций.
+// Ключ поля задается тегом `asti:"name"`, без тега — имя поля с маленькой буквы;
+// `asti:"-"` пропускает поле. Опции тега: required — аннотация обязательна,
+// default=значение — значение при отсутствии аннотации (указывается последней).
+// Поддерживаются строки, числа, bool, time.Duration, encoding.TextUnmarshaler,
+// указатели и слайсы этих типов (элементы — из массива, через запятую и из всех значений повторяющегося ключа),
+// а также map и вложенные структуры из значений-объектов {key: value}.
+// Для остальных типов повторяющегося ключа используется последнее значение.
+// Неизвестные ключи и ошибки преобразования возвращаются вместе через errors.Join.
+func (a Annotations) Decode(v any) (err error) {
+
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		err = fmt.Errorf("decode target must be a non-nil pointer to struct, got %T", v)
+		return
+	}
+	target = target.Elem()
+
+	var fields []decodeField
+	if fields, err = decodeFields(target.Type(), nil); err != nil {
+		return
+	}
+
+	var errs []error
+	known := make(map[string]bool, len(fields))
+	reported := make(map[string]bool)
+	for _, field := range fields {
+		known[field.key] = true
+		value, found := a.Lookup(field.key)
+		switch {
+		case found:
+		case field.required:
+			errs = append(errs, fmt.Errorf("%w: %s", ErrRequiredAnnotation, field.key))
+			continue
+		case field.defaultValue != nil:
+			value = NewValue(*field.defaultValue)
+		default:
+			continue
+		}
+		fieldValue := target.FieldByIndex(field.index)
+		values := []Value{value}
+		// Все значения повторяющегося ключа собираются в слайс
+		if found && fieldValue.Kind() == reflect.Slice && !isTextUnmarshaler(fieldValue) {
+			values = a.Values(field.key)
+		}
+		if decodeErr := decodeValues(fieldValue, values); decodeErr != nil {
+			errs = append(errs, withKey(field.key, decodeErr))
+		}
+	}
+	for _, annotation := range a {
+		if !known[annotation.Key] && !reported[annotation.Key] {
+			reported[annotation.Key] = true
+			errs = append(errs, fmt.Errorf("%s%w: %s", positionPrefix(annotation.Value.Position), ErrUnknownAnnotation, annotation.Key))
+		}
+	}
+	err = errors.Join(errs...)
+	return
+}
+
+// decodeFields собирает поля структуры с ключами аннотаций, включая встроенные структуры
+func decodeFields(structType reflect.Type, index []int) (fields []decodeField, err error) {
+
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		tag, tagged := structField.Tag.Lookup("asti")
+		if tag == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+		if structField.Anonymous && !tagged && structField.Type.Kind() == reflect.Struct {
+			var embedded []decodeField
+			if embedded, err = decodeFields(structField.Type, fieldIndex); err != nil {
+				return
+			}
+			fields = append(fields, embedded...)
+			continue
+		}
+		if !structField.IsExported() {
+			continue
+		}
+		field := decodeField{index: fieldIndex}
+		name, options, _ := strings.Cut(tag, ",")
+		field.key = name
+		if field.key == "" {
+			first, size := utf8.DecodeRuneInString(structField.Name)
+			field.key = string(unicode.ToLower(first)) + structField.Name[size:]
+		}
+		for options != "" {
+			var option string
+			if strings.HasPrefix(options, "default=") {
+				defaultValue := strings.TrimPrefix(options, "default=")
+				field.defaultValue = &defaultValue
+				break
+			}
+			option, options, _ = strings.Cut(options, ",")
+			switch option {
+			case "required":
+				field.required = true
+			default:
+				err = fmt.Errorf("field %s: unknown asti tag option %q", structField.Name, option)
+				return
+			}
+		}
+		fields = append(fields, field)
+	}
+	return
+}
+
+// isTextUnmarshaler сообщает, декодируется ли поле через encoding.TextUnmarshaler
+func isTextUnmarshaler(field reflect.Value) (ok bool) {
+
+	ok = field.Kind() != reflect.Pointer && field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType)
+	return
+}
+
+// decodeValues преобразует значения аннотации к типу поля; для слайса элементы всех значений
+// объединяются, для остальных типов используется последнее значение
+func decodeValues(field reflect.Value, values []Value) (err error) {
+
+	if len(values) == 1 || field.Kind() != reflect.Slice {
+		err = decodeValue(field, values[len(values)-1])
+		return
+	}
+	slice := reflect.MakeSlice(field.Type(), 0, len(values))
+	for _, value := range values {
+		element := reflect.New(field.Type()).Elem()
+		if err = decodeValue(element, value); err != nil {
+			return
+		}
+		slice = reflect.AppendSlice(slice, element)
+	}
+	field.Set(slice)
+	return
+}
+
+// decodeValue преобразует значение аннотации к типу поля
+func decodeValue(field reflect.Value, value Value) (err error) {
+
+	if isTextUnmarshaler(field) {
+		if err = field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value.String())); err != nil {
+			err = value.invalid(field.Type().String())
+		}
+		return
+	}
+	if field.Type() == durationType {
+		var duration time.Duration
+		if duration, err = value.Duration(); err == nil {
+			field.SetInt(int64(duration))
+		}
+		return
+	}
+
+	text := value.String()
+	switch field.Kind() {
+	case reflect.Pointer:
+		element := reflect.New(field.Type().Elem())
+		if err = decodeValue(element.Elem(), value); err == nil {
+			field.Set(element)
+		}
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		var parsed bool
+		if parsed, err = value.Bool(); err == nil {
+			field.SetBool(parsed)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var parsed int64
+		if parsed, err = strconv.ParseInt(text, 10, field.Type().Bits()); err != nil {
+			err = value.invalid("integer")
+			return
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var parsed uint64
+		if parsed, err = strconv.ParseUint(text, 10, field.Type().Bits()); err != nil {
+			err = value.invalid("unsigned integer")
+			return
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		var parsed float64
+		if parsed, err = strconv.ParseFloat(text, field.Type().Bits()); err != nil {
+			err = value.invalid("float")
+			return
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		items := value.Items
+		if value.Kind != KindArray {
+			var texts []string
+			if texts, err = value.List(); err != nil {
+				return
+			}
+			items = make([]Value, len(texts))
+			for i, text := range texts {
+				items[i] = Value{Raw: text, Kind: inferKind(text), Position: value.Position}
+			}
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err = decodeValue(slice.Index(i), item); err != nil {
+				return
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		var fields Annotations
+		if fields, err = value.Object(); err != nil {
+			return
+		}
+		entries := reflect.MakeMapWithSize(field.Type(), len(fields))
+		for _, entry := range fields {
+			key := reflect.New(field.Type().Key()).Elem()
+			keyValue := Value{Raw: entry.Key, Kind: inferKind(entry.Key), Position: entry.Value.Position}
+			if err = decodeValue(key, keyValue); err != nil {
+				return
+			}
+			element := reflect.New(field.Type().Elem()).Elem()
+			if err = decodeValue(element, entry.Value); err != nil {
+				return
+			}
+			entries.SetMapIndex(key, element)
+		}
+		field.Set(entries)
+	case reflect.Struct:
+		var fields Annotations
+		if fields, err = value.Object(); err != nil {
+			return
+		}
+		err = fields.Decode(field.Addr().Interface())
+	default:
+		err = fmt.Errorf("%sunsupported field type %s", positionPrefix(value.Position), field.Type())
+	}
+	return
+}
+
+// positionPrefix возвращает позицию в формате file:line:column: или пустую строку
+func positionPrefix(position Position) (prefix string) {
+
+	if position.File == "" && position.Line == 0 {
+		return
+	}
+	var builder strings.Builder
+	builder.WriteString(position.File)
+	if position.Line > 0 {
+		fmt.Fprintf(&builder, ":%d", position.Line)
+		if position.Column > 0 {
+			fmt.Fprintf(&builder, ":%d", position.Column)
+		}
+	}
+	builder.WriteString(": ")
+	prefix = builder.String()
+	return
+}
+
+// DecodeAnnotations заполняет структуру v аннотациями интерфейса, см. Annotations.Decode
+func (i Interface) DecodeAnnotations(v any) (err error) {
+
+	err = i.Annotations.Decode(v)
+	return
+}
+
+// DecodeAnnotations заполняет структуру v аннотациями метода, см. Annotations.Decode
+func (m MethodInfo) DecodeAnnotations(v any) (err error) {
+
+	err = m.Annotations.Decode(v)
+	return
+}
+
+// DecodeAnnotations заполняет структуру v аннотациями типа, см. Annotations.Decode
+func (t TypeInfo) DecodeAnnotations(v any) (err error) {
+
+	err = t.Annotations.Decode(v)
+	return
+}
+
+// DecodeAnnotations заполняет структуру v аннотациями поля, см. Annotations.Decode
+func (f FieldInfo) DecodeAnnotations(v any) (err error) {
+
+	err = f.Annotations.Decode(v)
+	return
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeConfig структура со всеми поддерживаемыми видами полей
+type decodeConfig struct {
+	Name    string         `asti:"name,required"`
+	Timeout time.Duration  `asti:"timeout"`
+	Retry   bool           `asti:"retry"`
+	Headers []string       `asti:"header"`
+	Codes   []int          `asti:"codes"`
+	Errors  map[int]string `asti:"errors"`
+	Limit   *int           `asti:"limit,default=100"`
+	Version string         `asti:"version,default=v1"`
+	Nested  struct {
+		Codes []int `asti:"codes"`
+	} `asti:"nested"`
+	Skipped string `asti:"-"`
+}
+
+// TestAnnotationsDecode проверяет декодирование значений разных видов и повторяющихся ключей
+func TestAnnotationsDecode(t *testing.T) {
+
+	limit := 5
+	parser := NewAnnotationParser("@asti")
+	for _, test := range []struct {
+		text     string
+		expected decodeConfig
+	}{
+		{
+			text:     "// @asti name=a timeout=30s retry",
+			expected: decodeConfig{Name: "a", Timeout: 30 * time.Second, Retry: true, Limit: intPointer(100), Version: "v1"},
+		},
+		{
+			text:     "// @asti name=a header=X-Request-ID header=X-Trace-ID limit=5 version=v2 version=v3",
+			expected: decodeConfig{Name: "a", Headers: []string{"X-Request-ID", "X-Trace-ID"}, Limit: &limit, Version: "v3"},
+		},
+		{
+			text:     "// @asti name=a codes=[400, 404] codes=500,503 header=[a, b] header=c",
+			expected: decodeConfig{Name: "a", Codes: []int{400, 404, 500, 503}, Headers: []string{"a", "b", "c"}, Limit: intPointer(100), Version: "v1"},
+		},
+		{
+			text: "// @asti name=a errors={404: NotFound, 409: \"Conflict\"} nested={codes: [502, 503]}",
+			expected: decodeConfig{Name: "a", Errors: map[int]string{404: "NotFound", 409: "Conflict"}, Limit: intPointer(100), Version: "v1",
+				Nested: struct {
+					Codes []int `asti:"codes"`
+				}{Codes: []int{502, 503}}},
+		},
+	} {
+		annotations, err := parser.Parse(context.Background(), test.text)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.text, err)
+			continue
+		}
+		var config decodeConfig
+		if err = annotations.Decode(&config); err != nil {
+			t.Errorf("%s: failed to decode: %v", test.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(config, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.text, test.expected, config)
+		}
+	}
+}
+
+// TestAnnotationsDecodeErrors проверяет ошибки декодирования с позициями значений
+func TestAnnotationsDecodeErrors(t *testing.T) {
+
+	parser := NewAnnotationParser("@asti")
+	for text, expected := range map[string][]string{
+		"// @asti timeout=1s":                         {ErrRequiredAnnotation.Error() + ": name"},
+		"// @asti name=a codes=[1, x]":                {"annotation codes: ", `invalid integer "x"`},
+		"// @asti name=a errors=[a]":                  {"annotation errors: ", `invalid object "[a]"`},
+		"// @asti name=a nested={codes: 1, other: 2}": {"1:42: " + ErrUnknownAnnotation.Error() + ": other"},
+		"// @asti name=a unknown=1 unknown=2":         {"1:25: " + ErrUnknownAnnotation.Error() + ": unknown"},
+	} {
+		annotations, err := parser.Parse(context.Background(), text)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", text, err)
+			continue
+		}
+		var config decodeConfig
+		err = annotations.Decode(&config)
+		if err == nil {
+			t.Errorf("%s: expected error", text)
+			continue
+		}
+		for _, part := range expected {
+			if !strings.Contains(err.Error(), part) {
+				t.Errorf("%s: expected %q in %v", text, part, err)
+			}
+		}
+		if strings.Count(err.Error(), "\n") != 0 {
+			t.Errorf("%s: expected a single error, got %v", text, err)
+		}
+	}
+
+	var valueErr *ValueError
+	annotations, _ := parser.Parse(context.Background(), "// @asti name=a timeout=fast")
+	if err := annotations.Decode(&decodeConfig{}); !errors.As(err, &valueErr) || valueErr.Key != "timeout" || valueErr.Position.Column != 25 {
+		t.Errorf("Expected positioned timeout error, got %v", err)
+	}
+	if err := annotations.Decode(decodeConfig{}); err == nil {
+		t.Error("Expected error for non-pointer target")
+	}
+	var tagged struct {
+		Name string `asti:"name,optional"`
+	}
+	if err := annotations.Decode(&tagged); err == nil || !strings.Contains(err.Error(), `unknown asti tag option "optional"`) {
+		t.Errorf("Expected unknown tag option error, got %v", err)
+	}
+}
+
+// intPointer возвращает указатель на value
+func intPointer(value int) (pointer *int) {
+
+	pointer = &value
+	return
+}
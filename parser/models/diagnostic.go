@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity важность диагностики
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Коды диагностик, которые формируют этапы pipeline
+const (
+	CodeSyntax           = "syntax"
+	CodeModuleNotFound   = "module-not-found"
+	CodeAnnotationSyntax = "annotation-syntax"
+	CodeValidation       = "validation"
+	CodeTypeCheck        = "type-check"
+	CodeAnnotationSchema = "annotation-schema"
+	CodeAnnotationTarget = "annotation-target"
+)
+
+// RelatedPosition дополнительное место в коде, связанное с диагностикой
+type RelatedPosition struct {
+	Position Position `json:"position"`
+	Message  string   `json:"message,omitempty"`
+}
+
+// Diagnostic проблема, найденная при разборе пакета
+type Diagnostic struct {
+	Severity Severity          `json:"severity"`
+	Code     string            `json:"code"`
+	Message  string            `json:"message"`
+	Position Position          `json:"position"`
+	Related  []RelatedPosition `json:"related,omitempty"`
+}
+
+// String возвращает диагностику в формате file:line:column: severity: message [code]
+func (d Diagnostic) String() (text string) {
+
+	var builder strings.Builder
+	if d.Position.File != "" {
+		builder.WriteString(d.Position.File)
+		if d.Position.Line > 0 {
+			fmt.Fprintf(&builder, ":%d", d.Position.Line)
+			if d.Position.Column > 0 {
+				fmt.Fprintf(&builder, ":%d", d.Position.Column)
+			}
+		}
+		builder.WriteString(": ")
+	}
+	fmt.Fprintf(&builder, "%s: %s", d.Severity, d.Message)
+	if d.Code != "" {
+		fmt.Fprintf(&builder, " [%s]", d.Code)
+	}
+	text = builder.String()
+	return
+}
+
+// Error позволяет использовать диагностику как ошибку
+func (d Diagnostic) Error() (text string) {
+
+	text = d.String()
+	return
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// ChangeKind вид изменения элемента модели
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// InterfaceChange изменение интерфейса пакета
+type InterfaceChange struct {
+	Kind ChangeKind `json:"kind"`
+	ID   string     `json:"id"`
+	Old  *Interface `json:"old,omitempty"`
+	New  *Interface `json:"new,omitempty"`
+	// Methods изменения методов интерфейса (для Kind == ChangeChanged)
+	Methods []MethodChange `json:"methods,omitempty"`
+}
+
+// MethodChange изменение метода интерфейса
+type MethodChange struct {
+	Kind ChangeKind `json:"kind"`
+	ID   string     `json:"id"`
+	Old  *Method    `json:"old,omitempty"`
+	New  *Method    `json:"new,omitempty"`
+}
+
+// TypeChange изменение типа пакета, Key — ключ типа в Package.Types
+type TypeChange struct {
+	Kind ChangeKind `json:"kind"`
+	Key  string     `json:"key"`
+	Old  *TypeInfo  `json:"old,omitempty"`
+	New  *TypeInfo  `json:"new,omitempty"`
+}
+
+// PackageDiff структурированная разница между двумя состояниями пакета
+type PackageDiff struct {
+	PackagePath string `json:"packagePath"`
+	// Package новое состояние пакета, nil если пакет удален
+	Package    *Package          `json:"package,omitempty"`
+	Interfaces []InterfaceChange `json:"interfaces,omitempty"`
+	Types      []TypeChange      `json:"types,omitempty"`
+}
+
+// Empty сообщает, что между состояниями пакета нет различий
+func (d *PackageDiff) Empty() (empty bool) {
+
+	empty = len(d.Interfaces) == 0 && len(d.Types) == 0
+	return
+}
+
+// DiffPackages сравнивает два состояния пакета; nil означает отсутствие пакета
+func DiffPackages(oldPackage *Package, newPackage *Package) (diff PackageDiff) {
+
+	diff.Package = newPackage
+	var oldInterfaces, newInterfaces []Interface
+	var oldTypes, newTypes map[string]TypeInfo
+	if oldPackage != nil {
+		diff.PackagePath = oldPackage.PackagePath
+		oldInterfaces, oldTypes = oldPackage.Interfaces, oldPackage.Types
+	}
+	if newPackage != nil {
+		diff.PackagePath = newPackage.PackagePath
+		newInterfaces, newTypes = newPackage.Interfaces, newPackage.Types
+	}
+	diff.Interfaces = diffInterfaces(oldInterfaces, newInterfaces)
+	diff.Types = diffTypes(oldTypes, newTypes)
+	return
+}
+
+func diffInterfaces(oldInterfaces []Interface, newInterfaces []Interface) (changes []InterfaceChange) {
+
+	oldByID := make(map[string]*Interface, len(oldInterfaces))
+	for i := range oldInterfaces {
+		oldByID[oldInterfaces[i].ID] = &oldInterfaces[i]
+	}
+	newByID := make(map[string]*Interface, len(newInterfaces))
+	for i := range newInterfaces {
+		newByID[newInterfaces[i].ID] = &newInterfaces[i]
+	}
+	for _, id := range unionKeys(oldByID, newByID) {
+		oldIface, newIface := oldByID[id], newByID[id]
+		switch {
+		case oldIface == nil:
+			changes = append(changes, InterfaceChange{Kind: ChangeAdded, ID: id, New: newIface})
+		case newIface == nil:
+			changes = append(changes, InterfaceChange{Kind: ChangeRemoved, ID: id, Old: oldIface})
+		case !sameJSON(oldIface, newIface):
+			changes = append(changes, InterfaceChange{
+				Kind:    ChangeChanged,
+				ID:      id,
+				Old:     oldIface,
+				New:     newIface,
+				Methods: diffMethods(oldIface.Methods, newIface.Methods),
+			})
+		}
+	}
+	return
+}
+
+func diffMethods(oldMethods []Method, newMethods []Method) (changes []MethodChange) {
+
+	oldByID := make(map[string]*Method, len(oldMethods))
+	for i := range oldMethods {
+		oldByID[oldMethods[i].ID] = &oldMethods[i]
+	}
+	newByID := make(map[string]*Method, len(newMethods))
+	for i := range newMethods {
+		newByID[newMethods[i].ID] = &newMethods[i]
+	}
+	for _, id := range unionKeys(oldByID, newByID) {
+		oldMethod, newMethod := oldByID[id], newByID[id]
+		switch {
+		case oldMethod == nil:
+			changes = append(changes, MethodChange{Kind: ChangeAdded, ID: id, New: newMethod})
+		case newMethod == nil:
+			changes = append(changes, MethodChange{Kind: ChangeRemoved, ID: id, Old: oldMethod})
+		case !sameJSON(oldMethod, newMethod):
+			changes = append(changes, MethodChange{Kind: ChangeChanged, ID: id, Old: oldMethod, New: newMethod})
+		}
+	}
+	return
+}
+
+func diffTypes(oldTypes map[string]TypeInfo, newTypes map[string]TypeInfo) (changes []TypeChange) {
+
+	for _, key := range unionKeys(oldTypes, newTypes) {
+		oldType, inOld := oldTypes[key]
+		newType, inNew := newTypes[key]
+		switch {
+		case !inOld:
+			changes = append(changes, TypeChange{Kind: ChangeAdded, Key: key, New: &newType})
+		case !inNew:
+			changes = append(changes, TypeChange{Kind: ChangeRemoved, Key: key, Old: &oldType})
+		case !sameJSON(oldType, newType):
+			changes = append(changes, TypeChange{Kind: ChangeChanged, Key: key, Old: &oldType, New: &newType})
+		}
+	}
+	return
+}
+
+// sameJSON сравнивает значения по JSON представлению, чтобы результаты из кэша
+// не отличались от свежих из-за nil и пустых коллекций
+func sameJSON(left any, right any) (same bool) {
+
+	leftJSON, leftErr := json.Marshal(left)
+	rightJSON, rightErr := json.Marshal(right)
+	same = leftErr == nil && rightErr == nil && bytes.Equal(leftJSON, rightJSON)
+	return
+}
+
+// unionKeys возвращает отсортированное объединение ключей двух карт
+func unionKeys[V any](left map[string]V, right map[string]V) (keys []string) {
+
+	seen := make(map[string]bool, len(left)+len(right))
+	for key := range left {
+		seen[key] = true
+	}
+	for key := range right {
+		seen[key] = true
+	}
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// AnnotationParserOption настройка DefaultAnnotationParser
+type AnnotationParserOption func(parser *DefaultAnnotationParser)
+
+// WithDirective задает имя директив "//name:key value". По умолчанию имя — префикс без @
+// (@asti — //asti:, @http — //http:); пустое имя отключает директивы.
+func WithDirective(name string) AnnotationParserOption {
+	return func(parser *DefaultAnnotationParser) {
+		parser.directive = name
+	}
+}
+
+// GetDirective возвращает имя директив парсера или пустую строку, если директивы отключены
+func (p *DefaultAnnotationParser) GetDirective() (directive string) {
+
+	directive = p.directive
+	return
+}
+
+// defaultDirective возвращает имя директив для префикса @name или пустую строку для других префиксов
+func defaultDirective(prefix string) (directive string) {
+
+	name, found := strings.CutPrefix(prefix, "@")
+	if !found || name == "" || strings.IndexFunc(name, func(char rune) bool {
+		return !unicode.IsLetter(char) && !unicode.IsDigit(char) && char != '_' && char != '-' && char != '.'
+	}) != -1 {
+		return
+	}
+	directive = name
+	return
+}
+
+// isDirective сообщает, является ли строка комментария директивой парсера. Как и у //go:generate,
+// между // и именем директивы нет пробела.
+func (p *DefaultAnnotationParser) isDirective(commentText string) (directive bool) {
+
+	directive = p.directive != "" && strings.HasPrefix(commentText, "//"+p.directive+":")
+	return
+}
+
+// parseDirective разбирает директиву после "//name:": ключ и значение до конца блока аннотации,
+// включая строки продолжения (после \, незакрытой строки или скобки, с отступом key=value).
+// Директива без значения — флаг (//asti:deprecated), значение из пар key=value —
+// объект (//asti:http method=GET path=/users), иначе значение разбирается целиком
+// (//asti:description User service, //asti:tags [a, b]). offset — смещение content в source.
+func (p *DefaultAnnotationParser) parseDirective(source commentSource, content string, offset int) (annotations Annotations, err error) {
+
+	annotations = Annotations{}
+	keyEnd := strings.IndexAny(content, " \t\n")
+	if keyEnd == -1 {
+		keyEnd = len(content)
+	}
+	key := content[:keyEnd]
+	if key == "" {
+		err = &SyntaxError{Position: source.position(offset), Message: "directive without key"}
+		return
+	}
+	rest := strings.TrimLeft(content[keyEnd:], " \t\n")
+	restOffset := offset + len(content) - len(rest)
+	rest = strings.TrimRight(rest, " \t\r\n")
+	if rest == "" {
+		annotations.Add(key, Value{Raw: "true", Kind: KindBool, Position: source.position(offset)})
+		return
+	}
+	position := func(restPosition int) (position Position) {
+		return source.position(restOffset + restPosition)
+	}
+	if errOffset, message := checkSyntax(rest); errOffset != -1 {
+		err = &SyntaxError{Position: position(errOffset), Message: message}
+		return
+	}
+
+	tokens := tokenize(rest)
+	pairs := true
+	for _, token := range tokens {
+		name, _, found := cutKey(token.text)
+		pairs = pairs && found && name != "" && !strings.ContainsAny(name, "[{")
+	}
+	var value Value
+	if !pairs {
+		if value, err = parseValue(rest, position); err != nil {
+			return
+		}
+		annotations.Add(key, value)
+		return
+	}
+	value = Value{Raw: rest, Kind: KindObject, Position: position(0), Fields: Annotations{}}
+	if err = p.parseTokens(&value.Fields, tokens, source, restOffset); err != nil {
+		return
+	}
+	annotations.Add(key, value)
+	return
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// TestParseDirective проверяет разбор директив //asti:key value во флаги, объекты и значения
+func TestParseDirective(t *testing.T) {
+
+	parser := NewAnnotationParser("@asti")
+	for text, expected := range map[string][]string{
+		"//asti:deprecated":                         {"deprecated=bool:true"},
+		"//asti:name UserService":                   {"name=string:UserService"},
+		"//asti:timeout 5s":                         {"timeout=duration:5s"},
+		"//asti:description Returns a user by id":   {"description=string:Returns a user by id"},
+		"//asti:tags [users, read]":                 {"tags=array:[users, read]"},
+		"//asti:http method=GET path=/users/{id}":   {"http=object:method=GET path=/users/{id}"},
+		"//asti:errors {404: NotFound}   ":          {"errors=object:{404: NotFound}"},
+		"//asti:summary Don't retry":                {"summary=string:Don't retry"},
+		"//asti:note first line \\\n// second line": {"note=string:first line \nsecond line"},
+		"//asti:tags [users,\n// read]":             {"tags=array:[users,\nread]"},
+	} {
+		annotations, err := parser.Parse(context.Background(), text)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", text, err)
+			continue
+		}
+		var actual []string
+		for _, annotation := range annotations {
+			actual = append(actual, annotation.Key+"="+string(annotation.Value.Kind)+":"+annotation.Value.Raw)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected %q, got %q", text, expected, actual)
+		}
+	}
+
+	annotations, _ := parser.Parse(context.Background(), "//asti:http method=GET path=/users")
+	if http, _ := annotations.Lookup("http"); http.Fields.Get("path") != "/users" || http.Fields[1].Value.Position.Column != 29 {
+		t.Errorf("Expected http path field at column 29, got %+v", http.Fields)
+	}
+	annotations, _ = parser.Parse(context.Background(), "//asti:http method=GET \\\n//   path=/users")
+	if http, _ := annotations.Lookup("http"); http.Fields.Get("path") != "/users" || http.Fields[1].Value.Position != (Position{Line: 2, Column: 11}) {
+		t.Errorf("Expected continued http path field at 2:11, got %+v", http.Fields)
+	}
+	for text, expected := range map[string]string{
+		"//asti: value":            "1:8: directive without key",
+		`//asti:title "abc`:        "1:14: unterminated string",
+		"//asti:tags [users, read": `1:13: unclosed "["`,
+		"//asti:http method=[GET":  `1:20: unclosed "["`,
+	} {
+		_, err := parser.Parse(context.Background(), text)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%s: expected syntax error, got %v", text, err)
+			continue
+		}
+		if actual := fmt.Sprintf("%d:%d: %s", syntaxErr.Position.Line, syntaxErr.Position.Column, syntaxErr.Message); actual != expected {
+			t.Errorf("%s: expected %s, got %s", text, expected, actual)
+		}
+	}
+}
+
+// TestDirectiveConfiguration проверяет имя директив и их отключение
+func TestDirectiveConfiguration(t *testing.T) {
+
+	for _, testCase := range []struct {
+		parser   *DefaultAnnotationParser
+		text     string
+		expected string
+	}{
+		{NewAnnotationParser("@asti"), "//asti:timeout 5s", "5s"},
+		{NewAnnotationParser("@asti", WithDirective("svc")), "//svc:timeout 5s", "5s"},
+		{NewAnnotationParser("@asti", WithDirective("svc")), "//asti:timeout 5s", ""},
+		{NewAnnotationParser("@asti", WithDirective("")), "//asti:timeout 5s", ""},
+		{NewAnnotationParser("@asti"), "// asti:timeout 5s", ""},
+	} {
+		annotations, err := testCase.parser.Parse(context.Background(), testCase.text)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", testCase.text, err)
+			continue
+		}
+		if timeout := annotations.Get("timeout"); timeout != testCase.expected {
+			t.Errorf("%s with directive %q: expected %q, got %q", testCase.text, testCase.parser.GetDirective(), testCase.expected, timeout)
+		}
+		if testCase.parser.Match(testCase.text) != (testCase.expected != "") {
+			t.Errorf("%s with directive %q: unexpected Match result", testCase.text, testCase.parser.GetDirective())
+		}
+	}
+	if directive := NewAnnotationParser("#asti").GetDirective(); directive != "" {
+		t.Errorf("Expected no directives for prefix without @, got %q", directive)
+	}
+}
@@ -6,6 +6,9 @@ type FieldInfo struct {
 	Type        string            `json:"type"`
 	Tags        map[string]string `json:"tags,omitempty"`
 	Annotations Annotations       `json:"annotations,omitempty"`
+	Namespaces  Namespaces        `json:"namespaces,omitempty"`
+	Effective   Annotations       `json:"effective,omitempty"`
+	Provenance  Provenance        `json:"provenance,omitempty"`
 	Position    Position          `json:"position"`
 	Embedded    bool              `json:"embedded,omitempty"`
 	Pointer     bool              `json:"pointer,omitempty"`
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// InheritMode правило наследования ключа аннотации нижним уровнем
+type InheritMode string
+
+const (
+	// InheritOverride собственное значение уровня заменяет все унаследованные значения ключа
+	InheritOverride InheritMode = "override"
+	// InheritAppend значения всех уровней объединяются, унаследованные идут первыми
+	InheritAppend InheritMode = "append"
+	// InheritNone ключ не наследуется, например name или method
+	InheritNone InheritMode = "none"
+)
+
+// InheritanceRules правила вычисления действующих аннотаций: пакет → интерфейс → метод, тип → поле
+type InheritanceRules struct {
+	// Default правило для ключей без собственного правила; пустое — InheritOverride
+	Default InheritMode `json:"default,omitempty"`
+	// Keys правила отдельных ключей
+	Keys map[string]InheritMode `json:"keys,omitempty"`
+}
+
+// Provenance уровни, с которых получены действующие значения ключей, в порядке Annotations.Values
+type Provenance map[string][]Target
+
+// Source возвращает уровень, с которого получено последнее значение ключа
+func (p Provenance) Source(key string) (source Target, found bool) {
+
+	if sources := p[key]; len(sources) > 0 {
+		source, found = sources[len(sources)-1], true
+	}
+	return
+}
+
+// Mode возвращает правило наследования ключа
+func (r InheritanceRules) Mode(key string) (mode InheritMode) {
+
+	if mode = r.Keys[key]; mode == "" {
+		if mode = r.Default; mode == "" {
+			mode = InheritOverride
+		}
+	}
+	return
+}
+
+// Check проверяет, что правила используют только известные режимы наследования
+func (r InheritanceRules) Check() (err error) {
+
+	knownModes := []InheritMode{"", InheritOverride, InheritAppend, InheritNone}
+	if !slices.Contains(knownModes, r.Default) {
+		err = fmt.Errorf("invalid inheritance rules: unknown default mode %q", r.Default)
+		return
+	}
+	keys := make([]string, 0, len(r.Keys))
+	for key := range r.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !slices.Contains(knownModes, r.Keys[key]) {
+			err = fmt.Errorf("invalid inheritance rules: %s: unknown mode %q", key, r.Keys[key])
+			return
+		}
+	}
+	return
+}
+
+// Resolve возвращает действующие аннотации уровня source: унаследованные из действующих аннотаций
+// родителя parent по правилам и собственные own. Возвращает nil, если аннотаций нет.
+func (r InheritanceRules) Resolve(parent Annotations, own Annotations, source Target) (effective Annotations) {
+
+	for _, annotation := range parent {
+		switch r.Mode(annotation.Key) {
+		case InheritNone:
+			continue
+		case InheritOverride:
+			if own.Has(annotation.Key) {
+				continue
+			}
+		}
+		effective = append(effective, annotation)
+	}
+	for _, annotation := range own {
+		annotation.Source = source
+		effective = append(effective, annotation)
+	}
+	return
+}
+
+// Source возвращает уровень, с которого получено последнее значение ключа действующих аннотаций
+func (a Annotations) Source(key string) (source Target, found bool) {
+
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i].Key == key {
+			source, found = a[i].Source, true
+			return
+		}
+	}
+	return
+}
+
+// WithProvenance возвращает копию аннотаций с уровнями значений из provenance,
+// например для действующих аннотаций, восстановленных из JSON
+func (a Annotations) WithProvenance(provenance Provenance) (annotations Annotations) {
+
+	if a == nil {
+		return
+	}
+	annotations = make(Annotations, len(a))
+	used := make(map[string]int, len(provenance))
+	for i, annotation := range a {
+		if sources := provenance[annotation.Key]; used[annotation.Key] < len(sources) {
+			annotation.Source = sources[used[annotation.Key]]
+			used[annotation.Key]++
+		}
+		annotations[i] = annotation
+	}
+	return
+}
+
+// Provenance возвращает уровни значений всех ключей; nil для аннотаций без уровней
+func (a Annotations) Provenance() (provenance Provenance) {
+
+	for _, annotation := range a {
+		if annotation.Source == "" {
+			continue
+		}
+		if provenance == nil {
+			provenance = make(Provenance)
+		}
+		provenance[annotation.Key] = append(provenance[annotation.Key], annotation.Source)
+	}
+	return
+}
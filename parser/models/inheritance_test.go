@@ -0,0 +1,50 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestInheritanceRulesCheck проверяет допустимые режимы наследования
+func TestInheritanceRulesCheck(t *testing.T) {
+
+	for _, test := range []struct {
+		rules    InheritanceRules
+		expected string
+	}{
+		{rules: InheritanceRules{}},
+		{rules: InheritanceRules{Default: InheritAppend, Keys: map[string]InheritMode{"name": InheritNone, "timeout": InheritOverride}}},
+		{rules: InheritanceRules{Default: "merge"}, expected: `invalid inheritance rules: unknown default mode "merge"`},
+		{rules: InheritanceRules{Keys: map[string]InheritMode{"tags": "merge"}}, expected: `invalid inheritance rules: tags: unknown mode "merge"`},
+	} {
+		err := test.rules.Check()
+		if actual := errorText(err); actual != test.expected {
+			t.Errorf("%+v: expected error %q, got %q", test.rules, test.expected, actual)
+		}
+	}
+}
+
+// TestInheritanceRulesResolve проверяет режимы override, append и none
+func TestInheritanceRulesResolve(t *testing.T) {
+
+	parent := Annotations{{Key: "timeout", Value: NewValue("30s")}, {Key: "tags", Value: NewValue("base")}, {Key: "name", Value: NewValue("Parent")}}
+	own := Annotations{{Key: "timeout", Value: NewValue("10s")}, {Key: "tags", Value: NewValue("read")}}
+	rules := InheritanceRules{Keys: map[string]InheritMode{"tags": InheritAppend, "name": InheritNone}}
+
+	effective := rules.Resolve(parent, own, TargetMethod)
+	var actual []string
+	for _, annotation := range effective {
+		actual = append(actual, annotation.Key+"="+annotation.Value.String()+"@"+string(annotation.Source))
+	}
+	if expected := []string{"tags=base@", "timeout=10s@method", "tags=read@method"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func errorText(err error) (text string) {
+
+	if err != nil {
+		text = err.Error()
+	}
+	return
+}
@@ -8,5 +8,8 @@ type Interface struct {
 	Import      string      `json:"import,omitempty"`
 	Methods     []Method    `json:"methods"`
 	Annotations Annotations `json:"annotations,omitempty"`
+	Namespaces  Namespaces  `json:"namespaces,omitempty"`
+	Effective   Annotations `json:"effective,omitempty"`
+	Provenance  Provenance  `json:"provenance,omitempty"`
 	Position    Position    `json:"position"`
 }
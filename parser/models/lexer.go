@@ -0,0 +1,188 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Лексика значений аннотаций: строки в двойных кавычках с экранированием как в Go ("a \"b\"", "\t"),
+// сырые строки в одинарных кавычках и обратных апострофах без экранирования ('C:\path', `a "b"`),
+// кавычка открывает строку только в начале значения, ключа или элемента (summary=Don't retry),
+// массивы [] и объекты {}. Токены разделяются любыми пробельными символами Unicode вне кавычек и скобок.
+
+// isQuote сообщает, открывает ли символ строку в кавычках
+func isQuote(char byte) (quote bool) {
+
+	quote = char == '"' || char == '\'' || char == '`'
+	return
+}
+
+// opensQuote сообщает, открывает ли кавычка text[i] строку: кавычка должна начинать значение, ключ
+// или элемент массива/объекта. Апостроф внутри слова (Don't) остается обычным символом.
+func opensQuote(text string, i int) (opens bool) {
+
+	if !isQuote(text[i]) {
+		return
+	}
+	if i == 0 || strings.IndexByte("=[{,:", text[i-1]) != -1 {
+		opens = true
+		return
+	}
+	previous, _ := utf8.DecodeLastRuneInString(text[:i])
+	opens = unicode.IsSpace(previous)
+	return
+}
+
+// scanQuoted возвращает смещение после закрывающей кавычки строки, начинающейся в text[start].
+// В двойных кавычках \ экранирует следующий символ. Для незакрытой строки ok равно false.
+func scanQuoted(text string, start int) (end int, ok bool) {
+
+	quote := text[start]
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			end, ok = i+1, true
+			return
+		}
+	}
+	end = len(text)
+	return
+}
+
+// isQuoted сообщает, является ли текст целиком строкой в кавычках
+func isQuoted(raw string) (quoted bool) {
+
+	if len(raw) < 2 || !isQuote(raw[0]) {
+		return
+	}
+	end, ok := scanQuoted(raw, 0)
+	quoted = ok && end == len(raw)
+	return
+}
+
+// unquote убирает обрамляющие кавычки и раскрывает экранирование в двойных кавычках
+func unquote(raw string) (text string) {
+
+	text = raw
+	switch {
+	case isQuoted(raw) && raw[0] == '"':
+		// Значение в кавычках может занимать несколько строк комментария
+		if unquoted, err := strconv.Unquote(strings.ReplaceAll(raw, "\n", `\n`)); err == nil {
+			text = unquoted
+			return
+		}
+		text = raw[1 : len(raw)-1]
+	case isQuoted(raw):
+		text = raw[1 : len(raw)-1]
+	}
+	return
+}
+
+// tokenize разбивает строку аннотации на токены, разделенные пробельными символами вне кавычек и скобок.
+// Синтаксис строки должен быть предварительно проверен checkSyntax.
+func tokenize(content string) (tokens []annotationToken) {
+
+	start := -1
+	depth := 0
+	for i := 0; i < len(content); {
+		char, size := utf8.DecodeRuneInString(content[i:])
+		switch {
+		case opensQuote(content, i):
+			if start == -1 {
+				start = i
+			}
+			i, _ = scanQuoted(content, i)
+			continue
+		case char == '[' || char == '{':
+			depth++
+		case char == ']' || char == '}':
+			depth--
+		case unicode.IsSpace(char) && depth == 0:
+			if start != -1 {
+				tokens = append(tokens, annotationToken{text: content[start:i], offset: start})
+				start = -1
+			}
+			i += size
+			continue
+		}
+		if start == -1 {
+			start = i
+		}
+		i += size
+	}
+	if start != -1 {
+		tokens = append(tokens, annotationToken{text: content[start:], offset: start})
+	}
+	return
+}
+
+// messageUnterminated сообщение об ошибке незакрытой строки в кавычках
+const messageUnterminated = "unterminated string"
+
+// checkSyntax проверяет закрытие строк в кавычках и парность скобок вне кавычек.
+// Возвращает смещение и текст первой ошибки или -1.
+func checkSyntax(text string) (offset int, message string) {
+
+	var stack []int
+	for i := 0; i < len(text); i++ {
+		switch char := text[i]; {
+		case opensQuote(text, i):
+			end, ok := scanQuoted(text, i)
+			if !ok {
+				offset, message = i, messageUnterminated
+				return
+			}
+			i = end - 1
+		case char == '[' || char == '{':
+			stack = append(stack, i)
+		case char == ']' || char == '}':
+			if len(stack) == 0 || closingBracket[text[stack[len(stack)-1]]] != char {
+				offset, message = i, fmt.Sprintf("unexpected %q", string(char))
+				return
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		offset = stack[len(stack)-1]
+		message = fmt.Sprintf("unclosed %q", string(text[offset]))
+		return
+	}
+	offset = -1
+	return
+}
+
+// Unfinished сообщает, остались ли в конце текста аннотации незакрытая строка в кавычках или скобка:
+// такая аннотация продолжается на следующей строке комментария
+func Unfinished(text string) (unfinished bool) {
+
+	offset, message := checkSyntax(text)
+	unfinished = offset != -1 && (message == messageUnterminated || strings.HasPrefix(message, "unclosed "))
+	return
+}
+
+// cutKey делит токен key=value по первому = вне кавычек. Ключ в кавычках ("my key"=1) раскрывается.
+func cutKey(token string) (key string, raw string, found bool) {
+
+	key = token
+	for i := 0; i < len(token); i++ {
+		if opensQuote(token, i) {
+			end, _ := scanQuoted(token, i)
+			i = end - 1
+			continue
+		}
+		if token[i] == '=' {
+			key, raw, found = token[:i], token[i+1:], true
+			break
+		}
+	}
+	key = unquote(strings.TrimSpace(key))
+	return
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// TestTokenize проверяет разбиение строки на токены и их смещения
+func TestTokenize(t *testing.T) {
+
+	for _, test := range []struct {
+		content  string
+		expected []string
+	}{
+		{content: "a=1 b=2", expected: []string{"0:a=1", "4:b=2"}},
+		{content: "  a=1\tb=2\u00a0c=3 ", expected: []string{"2:a=1", "6:b=2", "11:c=3"}},
+		{content: `title="a b" next=1`, expected: []string{`0:title="a b"`, "12:next=1"}},
+		{content: "params=[a, b] errors={404: x, 409: y}", expected: []string{"0:params=[a, b]", "14:errors={404: x, 409: y}"}},
+		{content: "summary=Don't retry", expected: []string{"0:summary=Don't", "14:retry"}},
+		{content: "path='C:\\a b' raw=`x y`", expected: []string{"0:path='C:\\a b'", "14:raw=`x y`"}},
+		{content: "", expected: nil},
+	} {
+		var actual []string
+		for _, token := range tokenize(test.content) {
+			actual = append(actual, fmt.Sprintf("%d:%s", token.offset, token.text))
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%q: expected %q, got %q", test.content, test.expected, actual)
+		}
+	}
+}
+
+// TestCutKey проверяет отделение ключа от значения
+func TestCutKey(t *testing.T) {
+
+	for _, test := range []struct {
+		token string
+		key   string
+		raw   string
+		found bool
+	}{
+		{token: "name=value", key: "name", raw: "value", found: true},
+		{token: "filter=a=b", key: "filter", raw: "a=b", found: true},
+		{token: `"my key"=v`, key: "my key", raw: "v", found: true},
+		{token: `"a=b"=v`, key: "a=b", raw: "v", found: true},
+		{token: "flag", key: "flag"},
+		{token: "it's=x", key: "it's", raw: "x", found: true},
+	} {
+		key, raw, found := cutKey(test.token)
+		if key != test.key || raw != test.raw || found != test.found {
+			t.Errorf("%s: expected %q %q %v, got %q %q %v", test.token, test.key, test.raw, test.found, key, raw, found)
+		}
+	}
+}
+
+// TestUnfinished проверяет определение незакрытых строк и скобок
+func TestUnfinished(t *testing.T) {
+
+	for text, expected := range map[string]bool{
+		`// @asti a=1`:                   false,
+		`// @asti description="Manages`:  true,
+		`// @asti path='C:\dir`:          true,
+		"// @asti params=[id,":           true,
+		"// @asti errors={404: [a, b]}":  false,
+		"// @asti params=a]":             false,
+		"// @asti summary=Don't retry":   false,
+		`// @asti text="a \"quoted\" b"`: false,
+	} {
+		if actual := Unfinished(text); actual != expected {
+			t.Errorf("%s: expected %v, got %v", text, expected, actual)
+		}
+	}
+}
+
+// TestParseQuoting проверяет кавычки, экранирование, разделители, Unicode и апострофы внутри слов
+func TestParseQuoting(t *testing.T) {
+
+	parser := NewAnnotationParser("@asti")
+	for text, expected := range map[string][]string{
+		"// @asti summary=Don't retry":                    {"summary=Don't", "retry=true"},
+		"// @asti note=it's ok=true":                      {"note=it's", "ok=true"},
+		"// @asti quote=O'Reilly's list=[it's, 'a b']":    {"quote=O'Reilly's", "list=[it's, 'a b']"},
+		`// @asti title="say \"hi\" now" next=1`:          {`title=say "hi" now`, "next=1"},
+		"// @asti path='C:\\temp\\new' query=`a \"b\" c`": {`path=C:\temp\new`, `query=a "b" c`},
+		`// @asti filter="a=b" "my key"=v`:                {"filter=a=b", "my key=v"},
+		`// @asti msg="line\tend" flag`:                   {"msg=line\tend", "flag=true"},
+		"// @asti\tname=x\tversion=2":                     {"name=x", "version=2"},
+		"// @asti path=C:\\dir\\":                         {`path=C:\dir\`},
+		"// @asti a=1 \\\n// b=2":                         {"a=1", "b=2"},
+		"/* @asti path=C:\\dir\\ */":                      {`path=C:\dir\`},
+		"// @asti описание=\"Сервис пользователей\" знак=✓ a=1\u00a0b=2": {"описание=Сервис пользователей", "знак=✓", "a=1", "b=2"},
+		`// @asti tags=["a, b", 'c]', d]`: {`tags=["a, b", 'c]', d]`},
+	} {
+		annotations, err := parser.Parse(context.Background(), text)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", text, err)
+			continue
+		}
+		var actual []string
+		for _, annotation := range annotations {
+			actual = append(actual, annotation.Key+"="+annotation.Value.String())
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected %q, got %q", text, expected, actual)
+		}
+	}
+
+	annotations, _ := parser.Parse(context.Background(), `// @asti tags=["a, b", 'c]', d]`)
+	if tags, _ := annotations.List("tags"); fmt.Sprint(tags) != "[a, b c] d]" {
+		t.Errorf("Expected quoted array items, got %q", tags)
+	}
+	annotations, _ = parser.Parse(context.Background(), `// @asti "my key"=v`)
+	if value, _ := annotations.Lookup("my key"); value.Position.Column != 19 {
+		t.Errorf("Expected value of quoted key at column 19, got %v", value.Position)
+	}
+}
+
+// TestParseSyntaxErrors проверяет позиции ошибок незакрытых строк и несбалансированных скобок
+func TestParseSyntaxErrors(t *testing.T) {
+
+	parser := NewAnnotationParser("@asti")
+	for text, expected := range map[string]string{
+		`// @asti title="abc`:              `1:16: unterminated string`,
+		"// @asti a=1 b=`x":                `1:16: unterminated string`,
+		`// @asti title="abc\"`:            `1:16: unterminated string`,
+		`// @asti a=1 b='x`:                `1:16: unterminated string`,
+		"// @asti params=[a, b":            `1:17: unclosed "["`,
+		"// @asti params=a]":               `1:18: unexpected "]"`,
+		"// @asti errors={404: [a, b}":     `1:28: unexpected "}"`,
+		"// @asti errors={404 NotFound}":   `1:30: expected ':' after object key "404 NotFound"`,
+		"// @asti errors={: NotFound}":     `1:18: empty object key`,
+		"// @asti =value":                  `1:10: empty annotation key`,
+		"// @asti a=1 ={a: 1}":             `1:14: empty annotation key`,
+		`// @asti ""=x`:                    `1:10: empty annotation key`,
+		"// @asti a=1\n// b=\"x\n// c=[1]": `2:6: unterminated string`,
+	} {
+		_, err := parser.Parse(context.Background(), text)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%s: expected syntax error, got %v", text, err)
+			continue
+		}
+		if actual := fmt.Sprintf("%d:%d: %s", syntaxErr.Position.Line, syntaxErr.Position.Column, syntaxErr.Message); actual != expected {
+			t.Errorf("%s: expected %s, got %s", text, expected, actual)
+		}
+	}
+}
+
+// TestSyntaxErrorWithBase проверяет пересчет позиции ошибки относительно комментария
+func TestSyntaxErrorWithBase(t *testing.T) {
+
+	base := Position{File: "service.go", Line: 7, Column: 2}
+	for _, test := range []struct {
+		position Position
+		expected string
+	}{
+		{position: Position{Line: 1, Column: 27}, expected: "service.go:7:28: unterminated string"},
+		{position: Position{Line: 2, Column: 5}, expected: "service.go:8:5: unterminated string"},
+		{position: Position{}, expected: "service.go:7:2: unterminated string"},
+	} {
+		err := (&SyntaxError{Position: test.position, Message: "unterminated string"}).WithBase(base)
+		if err.Error() != test.expected {
+			t.Errorf("%+v: expected %s, got %s", test.position, test.expected, err.Error())
+		}
+	}
+}
@@ -6,5 +6,8 @@ type MethodInfo struct {
 	Parameters  []Variable  `json:"parameters"`
 	Results     []Variable  `json:"results"`
 	Annotations Annotations `json:"annotations,omitempty"`
+	Namespaces  Namespaces  `json:"namespaces,omitempty"`
+	Effective   Annotations `json:"effective,omitempty"`
+	Provenance  Provenance  `json:"provenance,omitempty"`
 	Position    Position    `json:"position"`
 } 
\ No newline at end of file
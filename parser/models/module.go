@@ -0,0 +1,39 @@
+package models
+
+// Module представляет Go модуль со всеми разобранными пакетами
+type Module struct {
+	ModuleName string    `json:"moduleName"`
+	Root       string    `json:"root,omitempty"`
+	Packages   []Package `json:"packages"`
+}
+
+// Package возвращает пакет модуля по полному пути импорта
+func (m *Module) Package(importPath string) (pkg *Package, found bool) {
+
+	for i := range m.Packages {
+		if m.Packages[i].ImportPath() == importPath {
+			pkg = &m.Packages[i]
+			found = true
+			return
+		}
+	}
+	return
+}
+
+// LookupType ищет тип по пути импорта пакета и имени типа
+func (m *Module) LookupType(importPath string, typeName string) (typeInfo TypeInfo, found bool) {
+
+	var pkg *Package
+	if pkg, found = m.Package(importPath); !found {
+		return
+	}
+	found = false
+	for _, info := range pkg.Types {
+		if info.Name == typeName && info.Import == importPath {
+			typeInfo = info
+			found = true
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,39 @@
+package models
+
+// AnnotationNamespace дополнительное пространство имен аннотаций (@http, @grpc, @log) со своим парсером
+type AnnotationNamespace struct {
+	Name   string
+	Parser AnnotationParser
+}
+
+// NewAnnotationNamespace создает пространство имен name с парсером по умолчанию и префиксом @name
+func NewAnnotationNamespace(name string) (namespace AnnotationNamespace) {
+
+	namespace = AnnotationNamespace{Name: name, Parser: NewAnnotationParser("@" + name)}
+	return
+}
+
+// Namespaces аннотации элемента, сгруппированные по дополнительным пространствам имен:
+// Namespaces["http"].Get("path"). Аннотации основного префикса остаются в поле Annotations.
+type Namespaces map[string]Annotations
+
+// Get возвращает текст последнего значения ключа key пространства имен namespace или пустую строку
+func (n Namespaces) Get(namespace string, key string) (text string) {
+
+	text = n[namespace].Get(key)
+	return
+}
+
+// Merge добавляет аннотации пространства имен namespace, сохраняя повторяющиеся ключи
+func (n *Namespaces) Merge(namespace string, annotations Annotations) {
+
+	if *n == nil {
+		*n = make(Namespaces)
+	}
+	merged := (*n)[namespace]
+	if merged == nil {
+		merged = Annotations{}
+	}
+	merged.Merge(annotations)
+	(*n)[namespace] = merged
+}
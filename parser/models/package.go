@@ -5,6 +5,35 @@ type Package struct {
 	ModuleName  string              `json:"moduleName"`
 	PackagePath string              `json:"packagePath"`
 	Annotations Annotations         `json:"annotations"`
+	Namespaces  Namespaces          `json:"namespaces,omitempty"`
 	Interfaces  []Interface         `json:"interfaces"`
 	Types       map[string]TypeInfo `json:"types"`
+	Build       *BuildInfo          `json:"build,omitempty"`
+	Diagnostics []Diagnostic        `json:"diagnostics,omitempty"`
+}
+
+// ImportPath возвращает полный путь импорта пакета
+func (p *Package) ImportPath() (importPath string) {
+
+	switch {
+	case p.ModuleName == "":
+		importPath = p.PackagePath
+	case p.PackagePath == "" || p.PackagePath == ".":
+		importPath = p.ModuleName
+	default:
+		importPath = p.ModuleName + "/" + p.PackagePath
+	}
+	return
+}
+
+// HasErrors сообщает, есть ли среди диагностик пакета ошибки
+func (p *Package) HasErrors() (hasErrors bool) {
+
+	for _, diagnostic := range p.Diagnostics {
+		if diagnostic.Severity == SeverityError {
+			hasErrors = true
+			return
+		}
+	}
+	return
 }
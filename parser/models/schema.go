@@ -0,0 +1,236 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Target элемент кода, к которому относятся аннотации
+type Target string
+
+const (
+	TargetPackage   Target = "package"
+	TargetInterface Target = "interface"
+	TargetMethod    Target = "method"
+	TargetType      Target = "type"
+	TargetField     Target = "field"
+)
+
+// KeySchema описание допустимого ключа аннотации
+type KeySchema struct {
+	Name string `json:"name"`
+	// Kind тип значения; пустой — любое значение. KindFloat принимает и целые числа,
+	// KindList — и одиночное значение или массив.
+	Kind     ValueKind `json:"kind,omitempty"`
+	Enum     []string  `json:"enum,omitempty"`
+	Required bool      `json:"required,omitempty"`
+	// Repeated разрешает несколько значений ключа у одного элемента
+	Repeated    bool   `json:"repeated,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// TargetSchema допустимые аннотации одного вида элементов
+type TargetSchema struct {
+	Keys []KeySchema `json:"keys"`
+	// Exclusive группы ключей, из которых у элемента может быть не более одного
+	Exclusive [][]string `json:"exclusive,omitempty"`
+	// AllowUnknown разрешает ключи, не описанные в Keys
+	AllowUnknown bool `json:"allowUnknown,omitempty"`
+}
+
+// AnnotationSchema схема аннотаций по видам элементов; виды элементов без схемы не проверяются.
+type AnnotationSchema struct {
+	Targets map[Target]TargetSchema `json:"targets"`
+}
+
+// LoadAnnotationSchema читает схему аннотаций из JSON файла
+func LoadAnnotationSchema(path string) (schema *AnnotationSchema, err error) {
+
+	var content []byte
+	if content, err = os.ReadFile(path); err != nil {
+		err = fmt.Errorf("failed to read annotation schema: %w", err)
+		return
+	}
+	if schema, err = ParseAnnotationSchema(content); err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+	}
+	return
+}
+
+// ParseAnnotationSchema разбирает схему аннотаций из JSON
+func ParseAnnotationSchema(content []byte) (schema *AnnotationSchema, err error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	schema = &AnnotationSchema{}
+	if err = decoder.Decode(schema); err != nil {
+		err = fmt.Errorf("invalid annotation schema: %w", err)
+		schema = nil
+		return
+	}
+	if err = schema.Check(); err != nil {
+		schema = nil
+	}
+	return
+}
+
+// Check проверяет корректность самой схемы
+func (s *AnnotationSchema) Check() (err error) {
+
+	knownKinds := []ValueKind{"", KindString, KindInt, KindFloat, KindBool, KindDuration, KindList, KindArray, KindObject}
+	for target, targetSchema := range s.Targets {
+		switch target {
+		case TargetPackage, TargetInterface, TargetMethod, TargetType, TargetField:
+		default:
+			err = fmt.Errorf("invalid annotation schema: unknown target %q", target)
+			return
+		}
+		keys := make(map[string]bool, len(targetSchema.Keys))
+		for _, key := range targetSchema.Keys {
+			if key.Name == "" {
+				err = fmt.Errorf("invalid annotation schema: %s: key without name", target)
+				return
+			}
+			if !slices.Contains(knownKinds, key.Kind) {
+				err = fmt.Errorf("invalid annotation schema: %s.%s: unknown kind %q", target, key.Name, key.Kind)
+				return
+			}
+			keys[key.Name] = true
+		}
+		for _, group := range targetSchema.Exclusive {
+			for _, name := range group {
+				if !keys[name] && !targetSchema.AllowUnknown {
+					err = fmt.Errorf("invalid annotation schema: %s: exclusive key %q is not declared", target, name)
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
+// Validate проверяет аннотации элемента вида target. Позиция position используется
+// для нарушений, не связанных с конкретным значением (например, отсутствие ключа).
+func (s *AnnotationSchema) Validate(target Target, name string, annotations Annotations, position Position) (diagnostics []Diagnostic) {
+
+	targetSchema, found := s.Targets[target]
+	if !found {
+		return
+	}
+	report := func(position Position, format string, args ...any) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Code:     CodeAnnotationSchema,
+			Message:  fmt.Sprintf("%s %s: ", target, name) + fmt.Sprintf(format, args...),
+			Position: position,
+		})
+	}
+
+	keys := make(map[string]KeySchema, len(targetSchema.Keys))
+	for _, key := range targetSchema.Keys {
+		keys[key.Name] = key
+	}
+	for _, annotation := range annotations {
+		key, declared := keys[annotation.Key]
+		if !declared {
+			if targetSchema.AllowUnknown {
+				continue
+			}
+			message := fmt.Sprintf("unknown annotation %q", annotation.Key)
+			if suggestion := closestKey(annotation.Key, targetSchema.Keys); suggestion != "" {
+				message += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+			report(annotation.Value.Position, "%s", message)
+			continue
+		}
+		if err := checkKind(annotation.Value, key.Kind); err != nil {
+			report(annotation.Value.Position, "annotation %s: expected %s, got %q", key.Name, key.Kind, annotation.Value.Raw)
+			continue
+		}
+		if len(key.Enum) > 0 && !slices.Contains(key.Enum, annotation.Value.String()) {
+			report(annotation.Value.Position, "annotation %s: value %q is not one of %s", key.Name, annotation.Value.String(), strings.Join(key.Enum, ", "))
+		}
+	}
+	for _, key := range targetSchema.Keys {
+		if key.Required && !annotations.Has(key.Name) {
+			report(position, "missing required annotation %q", key.Name)
+		}
+		if values := annotations.Values(key.Name); len(values) > 1 && !key.Repeated {
+			report(values[1].Position, "annotation %s is repeated", key.Name)
+		}
+	}
+	for _, group := range targetSchema.Exclusive {
+		var present []string
+		for _, name := range group {
+			if annotations.Has(name) {
+				present = append(present, name)
+			}
+		}
+		if len(present) > 1 {
+			value, _ := annotations.Lookup(present[1])
+			report(value.Position, "annotations %s are mutually exclusive", strings.Join(present, ", "))
+		}
+	}
+	return
+}
+
+// checkKind проверяет, что значение преобразуется к типу kind
+func checkKind(value Value, kind ValueKind) (err error) {
+
+	switch kind {
+	case KindInt:
+		_, err = value.Int()
+	case KindFloat:
+		_, err = value.Float()
+	case KindBool:
+		_, err = value.Bool()
+	case KindDuration:
+		_, err = value.Duration()
+	case KindList:
+		_, err = value.List()
+	case KindArray:
+		_, err = value.Array()
+	case KindObject:
+		_, err = value.Object()
+	}
+	return
+}
+
+// closestKey возвращает объявленный ключ, отличающийся от key не более чем на две правки
+func closestKey(key string, keys []KeySchema) (closest string) {
+
+	best := 3
+	for _, candidate := range keys {
+		if distance := editDistance(key, candidate.Name); distance < best {
+			best, closest = distance, candidate.Name
+		}
+	}
+	return
+}
+
+// editDistance расстояние Левенштейна между строками
+func editDistance(a, b string) (distance int) {
+
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	distance = previous[len(b)]
+	return
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestParseAnnotationSchema проверяет ошибки в самой схеме
+func TestParseAnnotationSchema(t *testing.T) {
+
+	for content, expected := range map[string]string{
+		`{"targets": {"method": {"keys": [{"name": "timeout", "kind": "duration"}]}}}`:      "",
+		`{"targets": {"struct": {"keys": []}}}`:                                             `unknown target "struct"`,
+		`{"targets": {"method": {"keys": [{"kind": "int"}]}}}`:                              "method: key without name",
+		`{"targets": {"method": {"keys": [{"name": "timeout", "kind": "time"}]}}}`:          `method.timeout: unknown kind "time"`,
+		`{"targets": {"method": {"keys": [{"name": "a"}], "exclusive": [["a", "b"]]}}}`:     `method: exclusive key "b" is not declared`,
+		`{"targets": {"method": {"keys": [], "exclusive": [["a"]], "allowUnknown": true}}}`: "",
+		`{"targets": {}, "unknown": true}`:                                                  `unknown field "unknown"`,
+	} {
+		schema, err := ParseAnnotationSchema([]byte(content))
+		switch {
+		case expected == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", content, err)
+		case expected != "" && (err == nil || !strings.HasPrefix(err.Error(), "invalid annotation schema: ") || !strings.Contains(err.Error(), expected)):
+			t.Errorf("%s: expected error %s, got %v", content, expected, err)
+		case expected != "" && schema != nil:
+			t.Errorf("%s: expected no schema with error", content)
+		}
+	}
+}
+
+// TestAnnotationSchemaValidate проверяет диагностики нарушений схемы
+func TestAnnotationSchemaValidate(t *testing.T) {
+
+	schema := &AnnotationSchema{Targets: map[Target]TargetSchema{
+		TargetMethod: {
+			Keys: []KeySchema{
+				{Name: "method", Required: true},
+				{Name: "timeout", Kind: KindDuration},
+				{Name: "transport", Enum: []string{"http", "jsonrpc"}},
+				{Name: "tag", Repeated: true},
+				{Name: "http", Kind: KindBool},
+				{Name: "grpc", Kind: KindBool},
+			},
+			Exclusive: [][]string{{"http", "grpc"}},
+		},
+	}}
+	parser := NewAnnotationParser("@asti")
+	position := Position{File: "service.go", Line: 5, Column: 2}
+	for text, expected := range map[string]string{
+		"// @asti method=Get timeout=1s tag=a tag=b transport=http": "",
+		"// @asti timout=1s":                  `1:17: method Get: unknown annotation "timout", did you mean "timeout"?` + "\n" + `5:2: method Get: missing required annotation "method"`,
+		"// @asti method=Get timeout=fast":    `1:29: method Get: annotation timeout: expected duration, got "fast"`,
+		"// @asti method=Get transport=grpc":  `1:31: method Get: annotation transport: value "grpc" is not one of http, jsonrpc`,
+		"// @asti method=Get method=Find":     `1:28: method Get: annotation method is repeated`,
+		"// @asti method=Get http=true grpc":  `1:31: method Get: annotations http, grpc are mutually exclusive`,
+		"// @asti method=Get unknown=1 x=[1]": `1:29: method Get: unknown annotation "unknown"` + "\n" + `1:33: method Get: unknown annotation "x"`,
+	} {
+		annotations, err := parser.Parse(context.Background(), text)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", text, err)
+			continue
+		}
+		var actual []string
+		for _, diagnostic := range schema.Validate(TargetMethod, "Get", annotations, position) {
+			actual = append(actual, fmt.Sprintf("%d:%d: %s", diagnostic.Position.Line, diagnostic.Position.Column, diagnostic.Message))
+		}
+		if strings.Join(actual, "\n") != expected {
+			t.Errorf("%s: expected diagnostics:\n%s\ngot:\n%s", text, expected, strings.Join(actual, "\n"))
+		}
+	}
+	var actual []string
+	for _, diagnostic := range schema.Validate(TargetMethod, "Get", nil, position) {
+		actual = append(actual, diagnostic.String())
+	}
+	if expected := `service.go:5:2: error: method Get: missing required annotation "method" [annotation-schema]`; len(actual) != 1 || actual[0] != expected {
+		t.Errorf("Expected missing required key for element without annotations, got %v", actual)
+	}
+	if diagnostics := schema.Validate(TargetType, "User", Annotations{{Key: "any", Value: NewValue("1")}}, position); diagnostics != nil {
+		t.Errorf("Expected target without schema to be skipped, got %v", diagnostics)
+	}
+}
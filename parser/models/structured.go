@@ -0,0 +1,206 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError ошибка синтаксиса аннотации. Позиция отсчитывается так же, как позиции значений
+// парсера: от начала текста комментария, пока не пересчитана WithBase.
+type SyntaxError struct {
+	Position Position
+	Message  string
+}
+
+func (e *SyntaxError) Error() (text string) {
+
+	text = positionPrefix(e.Position) + e.Message
+	return
+}
+
+// WithBase возвращает ошибку с позицией относительно позиции комментария base
+func (e *SyntaxError) WithBase(base Position) (err *SyntaxError) {
+
+	err = &SyntaxError{Position: rebase(base, e.Position), Message: e.Message}
+	return
+}
+
+// rebase пересчитывает позицию парсера аннотаций относительно позиции комментария base
+func rebase(base Position, position Position) (rebased Position) {
+
+	switch {
+	case position.Line == 0:
+		rebased = base
+	case position.Line == 1:
+		rebased = Position{File: base.File, Line: base.Line, Column: base.Column + position.Column - 1}
+	default:
+		rebased = Position{File: base.File, Line: base.Line + position.Line - 1, Column: position.Column}
+	}
+	return
+}
+
+// closingBracket парная закрывающая скобка
+var closingBracket = map[byte]byte{'[': ']', '{': '}'}
+
+// valueParser разбирает значения с массивами [a, b] и объектами {key: value}
+type valueParser struct {
+	text     string
+	pos      int
+	position func(offset int) (position Position)
+}
+
+// parseValue разбирает исходный текст значения; position переводит смещение в тексте в позицию
+func parseValue(raw string, position func(offset int) (position Position)) (value Value, err error) {
+
+	if !strings.HasPrefix(raw, "[") && !strings.HasPrefix(raw, "{") {
+		value = Value{Raw: raw, Kind: inferKind(raw), Position: position(0)}
+		return
+	}
+	parser := &valueParser{text: raw, position: position}
+	if value, err = parser.parseStructured(); err != nil {
+		return
+	}
+	if parser.skipSpaces(); parser.pos < len(raw) {
+		err = parser.errorf("unexpected %q after %s", raw[parser.pos:parser.pos+1], value.Kind)
+	}
+	return
+}
+
+func (p *valueParser) errorf(format string, args ...any) (err error) {
+
+	err = &SyntaxError{Position: p.position(p.pos), Message: fmt.Sprintf(format, args...)}
+	return
+}
+
+func (p *valueParser) skipSpaces() {
+
+	for p.pos < len(p.text) && strings.IndexByte(" \t\r\n", p.text[p.pos]) != -1 {
+		p.pos++
+	}
+}
+
+// parseStructured разбирает массив или объект, начинающийся в текущей позиции
+func (p *valueParser) parseStructured() (value Value, err error) {
+
+	start := p.pos
+	open := p.text[p.pos]
+	value.Position = p.position(start)
+	p.pos++
+	if open == '[' {
+		value.Kind = KindArray
+	} else {
+		value.Kind = KindObject
+		value.Fields = Annotations{}
+	}
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.text) {
+			p.pos = start
+			err = p.errorf("unclosed %q", string(open))
+			return
+		}
+		if p.text[p.pos] == closingBracket[open] {
+			p.pos++
+			break
+		}
+		if open == '[' {
+			var item Value
+			if item, err = p.parseElement(",]"); err != nil {
+				return
+			}
+			value.Items = append(value.Items, item)
+		} else {
+			var key string
+			if key, err = p.parseKey(); err != nil {
+				return
+			}
+			var field Value
+			if field, err = p.parseElement(",}"); err != nil {
+				return
+			}
+			value.Fields.Add(key, field)
+		}
+		p.skipSpaces()
+		if p.pos < len(p.text) && p.text[p.pos] == ',' {
+			p.pos++
+		}
+	}
+	value.Raw = p.text[start:p.pos]
+	return
+}
+
+// parseKey разбирает ключ объекта до : или =
+func (p *valueParser) parseKey() (key string, err error) {
+
+	start := p.pos
+	if isQuote(p.text[p.pos]) {
+		var quoted Value
+		if quoted, err = p.parseQuoted(); err != nil {
+			return
+		}
+		key = quoted.String()
+	} else {
+		for p.pos < len(p.text) && strings.IndexByte(":=,}", p.text[p.pos]) == -1 {
+			p.pos++
+		}
+		key = strings.TrimSpace(p.text[start:p.pos])
+	}
+	p.skipSpaces()
+	if p.pos >= len(p.text) || (p.text[p.pos] != ':' && p.text[p.pos] != '=') {
+		err = p.errorf("expected ':' after object key %q", key)
+		return
+	}
+	if key == "" {
+		p.pos = start
+		err = p.errorf("empty object key")
+		return
+	}
+	p.pos++
+	return
+}
+
+// parseElement разбирает элемент массива или значение поля объекта до одного из terminators
+func (p *valueParser) parseElement(terminators string) (value Value, err error) {
+
+	p.skipSpaces()
+	if p.pos >= len(p.text) {
+		err = p.errorf("unexpected end of value")
+		return
+	}
+	switch char := p.text[p.pos]; {
+	case char == '[' || char == '{':
+		value, err = p.parseStructured()
+		return
+	case isQuote(char):
+		value, err = p.parseQuoted()
+		return
+	case char == ']' || char == '}':
+		err = p.errorf("unexpected %q", string(char))
+		return
+	}
+	start := p.pos
+	for p.pos < len(p.text) && strings.IndexByte(terminators, p.text[p.pos]) == -1 {
+		if char := p.text[p.pos]; char == '[' || char == '{' || char == ']' || char == '}' {
+			err = p.errorf("unexpected %q", string(char))
+			return
+		}
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.text[start:p.pos])
+	value = Value{Raw: raw, Kind: inferKind(raw), Position: p.position(start)}
+	return
+}
+
+// parseQuoted разбирает строку в кавычках
+func (p *valueParser) parseQuoted() (value Value, err error) {
+
+	start := p.pos
+	end, ok := scanQuoted(p.text, p.pos)
+	if !ok {
+		err = p.errorf("unterminated string")
+		return
+	}
+	p.pos = end
+	value = Value{Raw: p.text[start:p.pos], Kind: KindString, Position: p.position(start)}
+	return
+}
@@ -9,6 +9,9 @@ type TypeInfo struct {
 	Fields      []FieldInfo    `json:"fields,omitempty"`
 	Methods     []MethodInfo   `json:"methods,omitempty"`
 	Annotations Annotations    `json:"annotations,omitempty"`
+	Namespaces  Namespaces     `json:"namespaces,omitempty"`
+	Effective   Annotations    `json:"effective,omitempty"`
+	Provenance  Provenance     `json:"provenance,omitempty"`
 	Position    Position       `json:"position"`
 	Generic     *GenericInfo   `json:"generic,omitempty"`
 	Underlying  string         `json:"underlying,omitempty"`
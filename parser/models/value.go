@@ -0,0 +1,227 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueKind тип значения аннотации, распознанный грамматикой
+type ValueKind string
+
+const (
+	KindString   ValueKind = "string"
+	KindInt      ValueKind = "int"
+	KindFloat    ValueKind = "float"
+	KindBool     ValueKind = "bool"
+	KindDuration ValueKind = "duration"
+	KindList     ValueKind = "list"
+	KindArray    ValueKind = "array"
+	KindObject   ValueKind = "object"
+)
+
+// Value значение аннотации: исходный текст, распознанный тип и позиция в коде.
+// Массив [a, b] хранит элементы в Items, объект {key: value} — поля в Fields.
+type Value struct {
+	Raw      string      `json:"raw"`
+	Kind     ValueKind   `json:"kind"`
+	Position Position    `json:"position"`
+	Items    []Value     `json:"items,omitempty"`
+	Fields   Annotations `json:"fields,omitempty"`
+}
+
+// NewValue создает значение из исходного текста, определяя его тип.
+// Текст с несбалансированными скобками остается строкой.
+func NewValue(raw string) (value Value) {
+
+	var err error
+	if value, err = parseValue(raw, func(int) (position Position) { return }); err != nil {
+		value = Value{Raw: raw, Kind: KindString}
+	}
+	return
+}
+
+// inferKind определяет тип значения по исходному тексту
+func inferKind(raw string) (kind ValueKind) {
+
+	switch {
+	case isQuoted(raw):
+		kind = KindString
+	case raw == "true" || raw == "false":
+		kind = KindBool
+	case isInt(raw):
+		kind = KindInt
+	case isFloat(raw):
+		kind = KindFloat
+	case isDuration(raw):
+		kind = KindDuration
+	case len(splitList(raw)) > 1:
+		kind = KindList
+	default:
+		kind = KindString
+	}
+	return
+}
+
+func isInt(raw string) (ok bool) {
+
+	_, err := strconv.ParseInt(raw, 10, 64)
+	ok = err == nil
+	return
+}
+
+func isFloat(raw string) (ok bool) {
+
+	// strconv.ParseFloat принимает Inf и NaN, которые в аннотациях остаются строками
+	if !strings.ContainsAny(raw, "0123456789") || strings.ContainsAny(raw, "xXpP_") {
+		return
+	}
+	_, err := strconv.ParseFloat(raw, 64)
+	ok = err == nil
+	return
+}
+
+func isDuration(raw string) (ok bool) {
+
+	if !strings.ContainsAny(raw, "0123456789") {
+		return
+	}
+	_, err := time.ParseDuration(raw)
+	ok = err == nil
+	return
+}
+
+// splitList разбивает текст по запятым вне кавычек
+func splitList(raw string) (items []string) {
+
+	start := 0
+	for i := 0; i < len(raw); i++ {
+		switch {
+		case opensQuote(raw, i):
+			end, _ := scanQuoted(raw, i)
+			i = end - 1
+		case raw[i] == ',':
+			items = append(items, strings.TrimSpace(raw[start:i]))
+			start = i + 1
+		}
+	}
+	items = append(items, strings.TrimSpace(raw[start:]))
+	return
+}
+
+// String возвращает текст значения без кавычек
+func (v Value) String() (text string) {
+
+	text = unquote(v.Raw)
+	return
+}
+
+// Int возвращает значение как целое число
+func (v Value) Int() (value int, err error) {
+
+	if value, err = strconv.Atoi(v.String()); err != nil {
+		err = v.invalid("integer")
+	}
+	return
+}
+
+// Float возвращает значение как число с плавающей точкой
+func (v Value) Float() (value float64, err error) {
+
+	if value, err = strconv.ParseFloat(v.String(), 64); err != nil {
+		err = v.invalid("float")
+	}
+	return
+}
+
+// Bool возвращает значение как булево
+func (v Value) Bool() (value bool, err error) {
+
+	if value, err = strconv.ParseBool(v.String()); err != nil {
+		err = v.invalid("boolean")
+	}
+	return
+}
+
+// Duration возвращает значение как длительность (30s, 1m30s)
+func (v Value) Duration() (value time.Duration, err error) {
+
+	if value, err = time.ParseDuration(v.String()); err != nil {
+		err = v.invalid("duration")
+	}
+	return
+}
+
+// List возвращает элементы массива или значения, разделенного запятыми, без кавычек
+func (v Value) List() (values []string, err error) {
+
+	if v.Kind == KindArray {
+		for _, item := range v.Items {
+			values = append(values, item.String())
+		}
+		return
+	}
+	if v.Kind == KindObject {
+		err = v.invalid("list")
+		return
+	}
+	text := strings.TrimSpace(v.String())
+	if text == "" || v.Kind == KindString && isQuoted(v.Raw) {
+		if text != "" {
+			values = []string{text}
+		}
+		return
+	}
+	for _, item := range splitList(v.Raw) {
+		values = append(values, unquote(item))
+	}
+	return
+}
+
+// Array возвращает элементы значения-массива
+func (v Value) Array() (items []Value, err error) {
+
+	if v.Kind != KindArray {
+		err = v.invalid("array")
+		return
+	}
+	items = v.Items
+	return
+}
+
+// Object возвращает поля значения-объекта в порядке объявления
+func (v Value) Object() (fields Annotations, err error) {
+
+	if v.Kind != KindObject {
+		err = v.invalid("object")
+		return
+	}
+	fields = v.Fields
+	return
+}
+
+// invalid возвращает ошибку преобразования значения с его позицией
+func (v Value) invalid(expected string) (err error) {
+
+	err = &ValueError{Position: v.Position, Raw: v.Raw, Expected: expected}
+	return
+}
+
+// ValueError значение аннотации не соответствует запрошенному типу
+type ValueError struct {
+	Position Position
+	Key      string
+	Raw      string
+	Expected string
+}
+
+func (e *ValueError) Error() (text string) {
+
+	text = positionPrefix(e.Position)
+	if e.Key != "" {
+		text += fmt.Sprintf("annotation %s: ", e.Key)
+	}
+	text += fmt.Sprintf("invalid %s %q", e.Expected, e.Raw)
+	return
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// describeValue возвращает вид, исходный текст и колонку значения вместе с вложенными элементами и полями
+func describeValue(value Value) (text string) {
+
+	var builder strings.Builder
+	builder.WriteString(string(value.Kind) + ":" + value.Raw + "@" + strconv.Itoa(value.Position.Column))
+	for _, item := range value.Items {
+		builder.WriteString(" [" + describeValue(item) + "]")
+	}
+	for _, field := range value.Fields {
+		builder.WriteString(" {" + field.Key + "=" + describeValue(field.Value) + "}")
+	}
+	text = builder.String()
+	return
+}
+
+// TestParseValue проверяет распознавание видов значений, элементов, полей и их позиций
+func TestParseValue(t *testing.T) {
+
+	position := func(offset int) (position Position) {
+		return Position{Line: 1, Column: offset + 1}
+	}
+	for _, test := range []struct {
+		raw      string
+		expected string
+	}{
+		{raw: "text", expected: "string:text@1"},
+		{raw: `"404"`, expected: `string:"404"@1`},
+		{raw: "42", expected: "int:42@1"},
+		{raw: "-1.5", expected: "float:-1.5@1"},
+		{raw: "true", expected: "bool:true@1"},
+		{raw: "30s", expected: "duration:30s@1"},
+		{raw: "a,b", expected: "list:a,b@1"},
+		{raw: "[]", expected: "array:[]@1"},
+		{raw: "[id, 2]", expected: "array:[id, 2]@1 [string:id@2] [int:2@6]"},
+		{raw: `["a, b", 'c]']`, expected: `array:["a, b", 'c]']@1 [string:"a, b"@2] [string:'c]'@10]`},
+		{raw: "{}", expected: "object:{}@1"},
+		{raw: "{404: NotFound, retry=true}", expected: "object:{404: NotFound, retry=true}@1 {404=string:NotFound@7} {retry=bool:true@23}"},
+		{raw: `{"my key": 1s}`, expected: `object:{"my key": 1s}@1 {my key=duration:1s@12}`},
+		{raw: "{codes: [400, 404], ok: {a: 1}}", expected: "object:{codes: [400, 404], ok: {a: 1}}@1 {codes=array:[400, 404]@9 [int:400@10] [int:404@15]} {ok=object:{a: 1}@25 {a=int:1@29}}"},
+	} {
+		value, err := parseValue(test.raw, position)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.raw, err)
+			continue
+		}
+		if actual := describeValue(value); actual != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.raw, test.expected, actual)
+		}
+	}
+}
+
+// TestParseValueErrors проверяет ошибки синтаксиса массивов и объектов
+func TestParseValueErrors(t *testing.T) {
+
+	position := func(offset int) (position Position) {
+		return Position{File: "value", Line: 1, Column: offset + 1}
+	}
+	for raw, expected := range map[string]string{
+		"[a, b":           `value:1:1: unclosed "["`,
+		"{a: [1}":         `value:1:7: unexpected "}"`,
+		"{a 1}":           `value:1:5: expected ':' after object key "a 1"`,
+		"{:1}":            `value:1:2: empty object key`,
+		`["a]`:            `value:1:2: unterminated string`,
+		"[a] b":           `value:1:5: unexpected "b" after array`,
+		"{a: }":           `value:1:5: unexpected "}"`,
+		"[a, {b: [c]}, ]": "",
+	} {
+		_, err := parseValue(raw, position)
+		if expected == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", raw, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != expected {
+			t.Errorf("%s: expected %s, got %v", raw, expected, err)
+		}
+	}
+	if value := NewValue("[a, b"); value.Kind != KindString || value.Raw != "[a, b" {
+		t.Errorf("Expected unbalanced value to stay a string, got %+v", value)
+	}
+}
+
+// TestValueAccessors проверяет преобразование значений и ошибки с позицией значения
+func TestValueAccessors(t *testing.T) {
+
+	if number, err := NewValue("42").Int(); err != nil || number != 42 {
+		t.Errorf("Expected 42, got %d %v", number, err)
+	}
+	if number, err := NewValue("1.5").Float(); err != nil || number != 1.5 {
+		t.Errorf("Expected 1.5, got %v %v", number, err)
+	}
+	if flag, err := NewValue("true").Bool(); err != nil || !flag {
+		t.Errorf("Expected true, got %v %v", flag, err)
+	}
+	if duration, err := NewValue("30s").Duration(); err != nil || duration != 30*time.Second {
+		t.Errorf("Expected 30s, got %v %v", duration, err)
+	}
+	if text := NewValue(`"a \"b\""`).String(); text != `a "b"` {
+		t.Errorf("Expected unquoted string, got %s", text)
+	}
+
+	for raw, expected := range map[string][]string{
+		"a, b":         {"a", "b"},
+		`"a, b"`:       {"a, b"},
+		`[x, "y, z"]`:  {"x", "y, z"},
+		"single":       {"single"},
+		"[]":           nil,
+		`"it's", 'ok'`: {"it's", "ok"},
+	} {
+		if list, err := NewValue(raw).List(); err != nil || !reflect.DeepEqual(list, expected) {
+			t.Errorf("%s: expected list %q, got %q %v", raw, expected, list, err)
+		}
+	}
+
+	value := NewValue("{a: 1}")
+	value.Position = Position{File: "service.go", Line: 3, Column: 10}
+	for _, err := range []error{
+		func() (err error) { _, err = value.Int(); return }(),
+		func() (err error) { _, err = value.List(); return }(),
+		func() (err error) { _, err = value.Array(); return }(),
+	} {
+		var valueErr *ValueError
+		if !errors.As(err, &valueErr) || !strings.HasPrefix(err.Error(), "service.go:3:10: invalid ") {
+			t.Errorf("Expected positioned value error, got %v", err)
+		}
+	}
+	if fields, err := value.Object(); err != nil || fields.Get("a") != "1" {
+		t.Errorf("Expected object fields, got %+v %v", fields, err)
+	}
+}
@@ -1,15 +1,18 @@
 package models
 
-// Variable представляет переменную в сигнатуре метода или функции
+// Variable представляет переменную в сигнатуре метода или функции. Annotations и Namespaces
+// собираются из ключей метода param.имя.ключ / result.имя.ключ и комментариев рядом с переменной.
 type Variable struct {
-	Name     string `json:"name"`
-	Type     string `json:"type"`
-	Variadic bool   `json:"variadic,omitempty"`
-	Pointer  bool   `json:"pointer,omitempty"`
-	Slice    bool   `json:"slice,omitempty"`
-	Map      bool   `json:"map,omitempty"`
-	Channel  bool   `json:"channel,omitempty"`
-	Generic  bool   `json:"generic,omitempty"`
-	Array    bool   `json:"array,omitempty"`
-	ArrayLen int    `json:"arrayLen,omitempty"`
-} 
\ No newline at end of file
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Variadic    bool        `json:"variadic,omitempty"`
+	Pointer     bool        `json:"pointer,omitempty"`
+	Slice       bool        `json:"slice,omitempty"`
+	Map         bool        `json:"map,omitempty"`
+	Channel     bool        `json:"channel,omitempty"`
+	Generic     bool        `json:"generic,omitempty"`
+	Array       bool        `json:"array,omitempty"`
+	ArrayLen    int         `json:"arrayLen,omitempty"`
+	Annotations Annotations `json:"annotations,omitempty"`
+	Namespaces  Namespaces  `json:"namespaces,omitempty"`
+}
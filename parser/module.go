@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/seniorGolang/asti/parser/models"
+	"github.com/seniorGolang/asti/parser/pipeline"
+)
+
+// ParseModule парсит все пакеты модуля, расположенного в root (аналог шаблона ./...)
+func (p *Parser) ParseModule(ctx context.Context, root string) (module *models.Module, err error) {
+
+	var absRoot string
+	if absRoot, err = filepath.Abs(root); err != nil {
+		err = fmt.Errorf("failed to get absolute path: %w", err)
+		return
+	}
+	var dirs []string
+	if dirs, err = expandPatterns(absRoot, []string{"./..."}); err != nil {
+		return
+	}
+	module, err = p.parseDirs(ctx, absRoot, dirs)
+	return
+}
+
+// ParsePatterns парсит пакеты, заданные go-style шаблонами относительно текущей директории
+func (p *Parser) ParsePatterns(ctx context.Context, patterns ...string) (module *models.Module, err error) {
+
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	var baseDir string
+	if baseDir, err = os.Getwd(); err != nil {
+		err = fmt.Errorf("failed to get working directory: %w", err)
+		return
+	}
+	var dirs []string
+	if dirs, err = expandPatterns(baseDir, patterns); err != nil {
+		return
+	}
+	module, err = p.parseDirs(ctx, baseDir, dirs)
+	return
+}
+
+func (p *Parser) parseDirs(ctx context.Context, baseDir string, dirs []string) (module *models.Module, err error) {
+
+	if len(dirs) == 0 {
+		err = fmt.Errorf("no Go packages found")
+		return
+	}
+	module = &models.Module{}
+	if module.Root, err = pipeline.FindModuleRoot(dirs[0]); err != nil {
+		module.Root = baseDir
+		err = nil
+	}
+	var packages []*models.Package
+	if packages, err = p.ParsePackages(ctx, dirs...); err != nil {
+		return
+	}
+	for i, pkg := range packages {
+		dir := dirs[i]
+		if module.ModuleName == "" {
+			module.ModuleName = pkg.ModuleName
+		} else if pkg.ModuleName != module.ModuleName {
+			err = fmt.Errorf("package %s belongs to module %s, expected %s", dir, pkg.ModuleName, module.ModuleName)
+			return
+		}
+		module.Packages = append(module.Packages, *pkg)
+	}
+	resolveReferences(module)
+	return
+}
+
+// resolveReferences заменяет описания типов из других пакетов модуля их полной информацией и
+// переносит в пакет типы полей разрешенных типов, чтобы цепочка A→B→C была доступна из A
+func resolveReferences(module *models.Module) {
+
+	for i := range module.Packages {
+		pkg := &module.Packages[i]
+		resolver := referenceResolver{module: module, pkg: pkg, visited: make(map[string]bool)}
+		keys := make([]string, 0, len(pkg.Types))
+		for key := range pkg.Types {
+			keys = append(keys, key)
+		}
+		for _, key := range keys {
+			resolver.resolve(key, pkg.Types[key])
+		}
+	}
+}
+
+// referenceResolver состояние разрешения ссылок одного пакета; visited исключает повторный обход циклических типов
+type referenceResolver struct {
+	module  *models.Module
+	pkg     *models.Package
+	visited map[string]bool
+}
+
+// resolve разрешает тип key и рекурсивно типы его полей из пакетов модуля
+func (r *referenceResolver) resolve(key string, typeInfo models.TypeInfo) {
+
+	if typeInfo.Import == "" || typeInfo.Import == r.pkg.ImportPath() {
+		return
+	}
+	id := typeInfo.Import + "." + typeInfo.Name
+	if r.visited[id] {
+		return
+	}
+	r.visited[id] = true
+	resolved, found := r.module.LookupType(typeInfo.Import, typeInfo.Name)
+	if !found {
+		return
+	}
+	r.pkg.Types[key] = resolved
+	for _, field := range resolved.Fields {
+		depKey, dependency, depFound := r.dependency(resolved.Import, field.Type)
+		if !depFound {
+			continue
+		}
+		if existing, exists := r.pkg.Types[depKey]; exists {
+			dependency = existing
+		} else {
+			r.pkg.Types[depKey] = dependency
+		}
+		r.resolve(depKey, dependency)
+	}
+}
+
+// dependency находит тип поля fieldType, объявленного в пакете sourceImport: сначала среди типов
+// этого пакета, затем по короткому имени пакета, если оно однозначно в пределах модуля
+func (r *referenceResolver) dependency(sourceImport string, fieldType string) (key string, typeInfo models.TypeInfo, found bool) {
+
+	base := referenceBase(fieldType)
+	dot := strings.LastIndex(base, ".")
+	if dot == -1 {
+		return
+	}
+	if source, sourceFound := r.module.Package(sourceImport); sourceFound {
+		if typeInfo, found = source.Types[base]; found {
+			key = base
+			return
+		}
+	}
+	name := base[dot+1:]
+	packageName := base[:dot]
+	if idx := strings.LastIndex(packageName, "."); idx != -1 {
+		packageName = packageName[idx+1:]
+	}
+	var matches int
+	for i := range r.module.Packages {
+		importPath := r.module.Packages[i].ImportPath()
+		if path.Base(importPath) != packageName {
+			continue
+		}
+		if info, infoFound := r.module.LookupType(importPath, name); infoFound {
+			typeInfo = info
+			matches++
+		}
+	}
+	if found = matches == 1; found {
+		key = packageName + "." + name
+	}
+	return
+}
+
+// referenceBase возвращает имя типа без указателей, срезов, массивов, каналов, ключей карт и параметров дженериков
+func referenceBase(typeStr string) (base string) {
+
+	base = typeStr
+	for {
+		switch {
+		case strings.HasPrefix(base, "*"):
+			base = base[1:]
+		case strings.HasPrefix(base, "..."):
+			base = base[3:]
+		case strings.HasPrefix(base, "chan "), strings.HasPrefix(base, "chan<- "), strings.HasPrefix(base, "<-chan "):
+			base = base[strings.Index(base, " ")+1:]
+		case strings.HasPrefix(base, "map["), strings.HasPrefix(base, "["):
+			depth := 0
+			for i, char := range base {
+				if char == '[' {
+					depth++
+				} else if char == ']' {
+					if depth--; depth == 0 {
+						base = base[i+1:]
+						break
+					}
+				}
+			}
+			if depth != 0 {
+				return
+			}
+		default:
+			if idx := strings.Index(base, "["); idx != -1 {
+				base = base[:idx]
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// TestParsePatterns проверяет парсинг нескольких пакетов по шаблону ./...
+func TestParsePatterns(t *testing.T) {
+	p := NewParser()
+
+	module, err := p.ParsePatterns(context.Background(), "../examples/sample/...")
+	if err != nil {
+		t.Fatalf("Failed to parse patterns: %v", err)
+	}
+
+	if module.ModuleName != "github.com/seniorGolang/asti" {
+		t.Errorf("Expected module name 'github.com/seniorGolang/asti', got '%s'", module.ModuleName)
+	}
+	if len(module.Packages) != 2 {
+		t.Fatalf("Expected 2 packages, got %d", len(module.Packages))
+	}
+
+	sample, found := module.Package("github.com/seniorGolang/asti/examples/sample")
+	if !found {
+		t.Fatal("Package examples/sample not found in module")
+	}
+	if _, found = module.Package("github.com/seniorGolang/asti/examples/sample/dto"); !found {
+		t.Fatal("Package examples/sample/dto not found in module")
+	}
+
+	// Тип из соседнего пакета должен быть разрешен в полноценную структуру
+	someUser, found := sample.Types["dto.SomeUser"]
+	if !found {
+		t.Fatal("Type dto.SomeUser not found in examples/sample")
+	}
+	if someUser.Kind != models.TypeStruct {
+		t.Errorf("Expected dto.SomeUser to be resolved as struct, got %s", someUser.Kind)
+	}
+	if len(someUser.Fields) != 1 || someUser.Fields[0].Name != "Name" {
+		t.Errorf("Expected dto.SomeUser to have field Name, got %+v", someUser.Fields)
+	}
+}
+
+// TestParseModule проверяет парсинг всех пакетов от корневой директории
+func TestParseModule(t *testing.T) {
+	p := NewParser()
+
+	module, err := p.ParseModule(context.Background(), "../examples/complex")
+	if err != nil {
+		t.Fatalf("Failed to parse module: %v", err)
+	}
+
+	// Корневой пакет examples/complex и семь подпакетов со сценариями
+	if len(module.Packages) != 8 {
+		t.Errorf("Expected 8 packages, got %d", len(module.Packages))
+	}
+	for _, pkg := range module.Packages {
+		if pkg.ModuleName != module.ModuleName {
+			t.Errorf("Package %s has module name %s, expected %s", pkg.PackagePath, pkg.ModuleName, module.ModuleName)
+		}
+	}
+}
+
+// TestParseModuleTransitive проверяет разрешение типов по цепочке пакетов A→B→C
+func TestParseModuleTransitive(t *testing.T) {
+
+	root := t.TempDir()
+	for _, dir := range []string{"a", "b", "c"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module github.com/test/chain\n\ngo 1.24\n")
+	writeTestFile(t, filepath.Join(root, "a", "a.go"), `package a
+
+import (
+	"context"
+
+	"github.com/test/chain/b"
+)
+
+// @asti name=Service
+type Service interface {
+	Get(ctx context.Context) (user b.User, err error)
+}
+`)
+	writeTestFile(t, filepath.Join(root, "b", "b.go"), `package b
+
+import "github.com/test/chain/c"
+
+type User struct {
+	Name     string
+	Address  *c.Address
+	Previous map[string][]c.Address
+}
+`)
+	writeTestFile(t, filepath.Join(root, "c", "c.go"), `package c
+
+type Address struct {
+	City string
+	Next *Address
+}
+`)
+
+	for name, options := range map[string][]Option{
+		"ast":          nil,
+		"typeChecking": {WithTypeChecking()},
+	} {
+		module, err := NewParser(options...).ParseModule(context.Background(), root)
+		if err != nil {
+			t.Fatalf("%s: failed to parse module: %v", name, err)
+		}
+		pkg, found := module.Package("github.com/test/chain/a")
+		if !found {
+			t.Fatalf("%s: package a not found in module", name)
+		}
+		if user := pkg.Types["b.User"]; len(user.Fields) != 3 {
+			t.Errorf("%s: expected b.User to be resolved with 3 fields, got %+v", name, user)
+		}
+		address, found := pkg.Types["c.Address"]
+		if !found {
+			t.Fatalf("%s: expected c.Address to be resolved in package a, got %v", name, pkg.Types)
+		}
+		if address.Kind != models.TypeStruct || len(address.Fields) != 2 || address.Fields[0].Name != "City" {
+			t.Errorf("%s: expected c.Address to be resolved as struct, got %+v", name, address)
+		}
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+
+	testCases := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"/mod/...", "/mod", true},
+		{"/mod/...", "/mod/internal/service", true},
+		{"/mod/internal/...", "/mod/internal", true},
+		{"/mod/internal/...", "/mod/pkg", false},
+		{"/mod/internal/...", "/mod/internalx", false},
+		{"/mod/svc.../api", "/mod/svc1/api", true},
+		{"/mod/svc.../api", "/mod/svc1/web", false},
+	}
+
+	for _, tc := range testCases {
+		if result := matchPattern(tc.pattern)(tc.path); result != tc.expected {
+			t.Errorf("matchPattern(%q)(%q) = %v, expected %v", tc.pattern, tc.path, result, tc.expected)
+		}
+	}
+}
+
+func TestExpandPatterns(t *testing.T) {
+
+	baseDir, err := filepath.Abs("../examples")
+	if err != nil {
+		t.Fatalf("Failed to get absolute path: %v", err)
+	}
+
+	dirs, err := expandPatterns(baseDir, []string{"./sample/...", "./sample"})
+	if err != nil {
+		t.Fatalf("expandPatterns failed: %v", err)
+	}
+	expected := []string{
+		filepath.Join(baseDir, "sample"),
+		filepath.Join(baseDir, "sample", "dto"),
+	}
+	if len(dirs) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, dirs)
+	}
+	for i := range expected {
+		if dirs[i] != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], dirs[i])
+		}
+	}
+}
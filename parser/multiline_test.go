@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+const multilineServiceFile = `package service
+
+import "context"
+
+// UserService сервис пользователей
+// @asti name=UserService \
+// version=2
+// @asti description="Manages users
+// and their profiles"
+type UserService interface {
+	// @asti method=Find
+	//     http.path=/users/{id}/orders/{orderID}
+	//     http.method=GET
+	// Find ищет пользователя
+	Find(ctx context.Context, id string) (err error)
+	/* @asti method=Update
+	   timeout=5s */
+	Update(ctx context.Context, id string) (err error)
+}
+
+/*
+ * @asti table=users
+ * schema=public
+ */
+type User struct {
+	ID string
+}
+`
+
+// TestMultilineAnnotations проверяет продолжения строк, блочные комментарии и позиции значений
+func TestMultilineAnnotations(t *testing.T) {
+
+	result := parseService(t, multilineServiceFile)
+	if len(result.Interfaces) != 1 || len(result.Interfaces[0].Methods) != 2 {
+		t.Fatalf("Expected 1 interface with 2 methods, got %+v", result.Interfaces)
+	}
+	iface := result.Interfaces[0]
+	find, update := iface.Methods[0], iface.Methods[1]
+
+	checks := []struct {
+		annotations models.Annotations
+		key         string
+		text        string
+		position    models.Position
+	}{
+		{iface.Annotations, "name", "UserService", models.Position{File: "service.go", Line: 6, Column: 15}},
+		{iface.Annotations, "version", "2", models.Position{File: "service.go", Line: 7, Column: 12}},
+		{iface.Annotations, "description", "Manages users\nand their profiles", models.Position{File: "service.go", Line: 8, Column: 22}},
+		{find.Annotations, "http.path", "/users/{id}/orders/{orderID}", models.Position{File: "service.go", Line: 12, Column: 19}},
+		{find.Annotations, "http.method", "GET", models.Position{File: "service.go", Line: 13, Column: 21}},
+		{update.Annotations, "method", "Update", models.Position{File: "service.go", Line: 16, Column: 18}},
+		{update.Annotations, "timeout", "5s", models.Position{File: "service.go", Line: 17, Column: 13}},
+		{result.Types["service.User"].Annotations, "table", "users", models.Position{File: "service.go", Line: 22, Column: 16}},
+		{result.Types["service.User"].Annotations, "schema", "public", models.Position{File: "service.go", Line: 23, Column: 11}},
+	}
+	for _, check := range checks {
+		value, found := check.annotations.Lookup(check.key)
+		if !found {
+			t.Errorf("Annotation %s not found", check.key)
+			continue
+		}
+		if value.String() != check.text {
+			t.Errorf("Expected %s=%q, got %q", check.key, check.text, value.String())
+		}
+		if value.Position != check.position {
+			t.Errorf("Expected %s at %+v, got %+v", check.key, check.position, value.Position)
+		}
+	}
+	if find.Annotations.Has("Find") {
+		t.Errorf("Expected plain doc line not to be parsed as annotation, got %v", find.Annotations.Keys())
+	}
+}
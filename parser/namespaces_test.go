@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+const namespacesServiceFile = `package service
+
+import "context"
+
+// @asti name=UserService
+// @http prefix=/api
+type UserService interface {
+	// @asti method=Get
+	// @http method=GET path=/users/{id}
+	// @grpc name=GetUser
+	// log: level=debug
+	Get(ctx context.Context, id string) (err error)
+}
+
+// @http prefix=/orders
+type OrderService interface {
+	// @http method=POST
+	Create(ctx context.Context) (err error)
+}
+
+// User пользователь
+// @grpc message=User
+type User struct {
+	// @http query=id
+	ID string
+}
+`
+
+// logParser собственный парсер аннотаций без models.AnnotationMatcher: строки вида "// log: key=value"
+type logParser struct{}
+
+func (logParser) Parse(_ context.Context, text string) (annotations models.Annotations, err error) {
+
+	content, found := strings.CutPrefix(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "//")), "log:")
+	if !found {
+		return
+	}
+	for _, field := range strings.Fields(content) {
+		key, value, _ := strings.Cut(field, "=")
+		annotations.Add(key, models.NewValue(value))
+	}
+	return
+}
+
+// TestAnnotationNamespaces проверяет разбор нескольких пространств имен за один проход
+func TestAnnotationNamespaces(t *testing.T) {
+
+	options := []Option{WithAnnotationNamespaces("http", "grpc"), WithAnnotationNamespace("log", logParser{})}
+	if names := NewParser(options...).Namespaces(); !reflect.DeepEqual(names, []string{"http", "grpc", "log"}) {
+		t.Errorf("Unexpected namespaces %v", names)
+	}
+	result := parseService(t, namespacesServiceFile, options...)
+	if len(result.Interfaces) != 2 {
+		t.Fatalf("Expected interface annotated only with @http to be collected, got %d interfaces", len(result.Interfaces))
+	}
+	userService, orderService := result.Interfaces[0], result.Interfaces[1]
+	method := userService.Methods[0]
+	user := result.Types["service.User"]
+	position := method.Namespaces["http"][1].Value.Position
+	_, restored := roundtrip(t, result)
+
+	checkExpectations(t, []expectation{
+		{name: "interface name", actual: userService.Annotations.Get("name"), expected: "UserService"},
+		{name: "interface http prefix", actual: userService.Namespaces.Get("http", "prefix"), expected: "/api"},
+		{name: "method @asti keys", actual: fmt.Sprint(method.Annotations.Keys()), expected: "[method]"},
+		{name: "method name", actual: method.Annotations.Get("method"), expected: "Get"},
+		{name: "method http path", actual: method.Namespaces["http"].Get("path"), expected: "/users/{id}"},
+		{name: "method grpc name", actual: method.Namespaces.Get("grpc", "name"), expected: "GetUser"},
+		{name: "method log level", actual: method.Namespaces.Get("log", "level"), expected: "debug"},
+		{name: "method http path position", actual: fmt.Sprintf("%d:%d", position.Line, position.Column), expected: "9:27"},
+		{name: "@http only interface annotations", actual: fmt.Sprint(orderService.Annotations == nil), expected: "true"},
+		{name: "@http only interface method", actual: orderService.Methods[0].Namespaces.Get("http", "method"), expected: "POST"},
+		{name: "type grpc message", actual: user.Namespaces.Get("grpc", "message"), expected: "User"},
+		{name: "field http query", actual: user.Fields[0].Namespaces.Get("http", "query"), expected: "id"},
+		{name: "http path after roundtrip", actual: restored.Interfaces[0].Methods[0].Namespaces.Get("http", "path"), expected: "/users/{id}"},
+	})
+}
+
+// TestCustomAnnotationParser проверяет собственную реализацию основного парсера аннотаций
+func TestCustomAnnotationParser(t *testing.T) {
+
+	result := parseService(t, "package service\n\nimport \"context\"\n\n// log: level=info\ntype LogService interface {\n\t// log: level=debug\n\tLog(ctx context.Context) (err error)\n}\n", WithAnnotationParser(logParser{}))
+	if len(result.Interfaces) != 1 || result.Interfaces[0].Methods[0].Annotations.Get("level") != "debug" {
+		t.Errorf("Expected custom parser annotations, got %+v", result.Interfaces)
+	}
+}
@@ -1,5 +1,16 @@
 package parser
 
+import (
+	"go/build"
+	"path/filepath"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
 type Option func(parser *Parser)
 
 func WithAnnotationPrefix(prefix string) Option {
@@ -7,3 +18,106 @@ func WithAnnotationPrefix(prefix string) Option {
 		parser.annotationPrefix = prefix
 	}
 }
+
+// WithAnnotationParser заменяет парсер аннотаций основного префикса собственной реализацией.
+// Если парсер реализует models.AnnotationMatcher, строки комментариев отбираются через Match.
+func WithAnnotationParser(annotationParser models.AnnotationParser) Option {
+	return func(parser *Parser) {
+		parser.annotationParser = annotationParser
+	}
+}
+
+// WithAnnotationNamespaces добавляет пространства имен аннотаций с префиксами @имя (@http, @grpc),
+// которые разбираются за один проход вместе с основным префиксом. Аннотации пространства имен
+// попадают в поле Namespaces элементов: method.Namespaces["http"].Get("path").
+func WithAnnotationNamespaces(names ...string) Option {
+	return func(parser *Parser) {
+		for _, name := range names {
+			parser.addNamespace(models.NewAnnotationNamespace(name))
+		}
+	}
+}
+
+// WithAnnotationNamespace добавляет пространство имен name с собственным парсером аннотаций
+func WithAnnotationNamespace(name string, annotationParser models.AnnotationParser) Option {
+	return func(parser *Parser) {
+		parser.addNamespace(models.AnnotationNamespace{Name: name, Parser: annotationParser})
+	}
+}
+
+// WithTypeChecking включает уточнение типов через go/types (golang.org/x/tools/go/packages)
+func WithTypeChecking() Option {
+	return func(parser *Parser) {
+		parser.typeChecking = true
+	}
+}
+
+// WithConcurrency ограничивает число пакетов, разбираемых одновременно в ParsePackages,
+// ParseModule и ParsePatterns. По умолчанию — runtime.GOMAXPROCS(0).
+func WithConcurrency(concurrency int) Option {
+	return func(parser *Parser) {
+		parser.concurrency = concurrency
+	}
+}
+
+// WithCache включает кэш результатов ParsePackage в директории dir. Результат пакета
+// берется из кэша, пока не изменились его Go файлы, go.mod и опции парсера. С собственными этапами
+// (WithStages, WithStageBefore, WithStageAfter), хуками и middleware (WithHooks, WithMiddleware)
+// и правилами WithFilterRules, WithValidationRules кэш не используется: их поведение не входит в ключ. С WithTypeChecking кэш тоже не используется,
+// так как уточненные типы зависят от исходников других пакетов.
+func WithCache(dir string) Option {
+	return func(parser *Parser) {
+		parser.cache = newPackageCache(dir)
+	}
+}
+
+// WithWatchInterval задает интервал опроса файлов в Watch (по умолчанию 500ms)
+func WithWatchInterval(interval time.Duration) Option {
+	return func(parser *Parser) {
+		parser.watchInterval = interval
+	}
+}
+
+// WithPartialResults включает режим частичного результата: файлы с синтаксическими ошибками
+// разбираются с восстановлением (parser.AllErrors), интерфейсы и типы из корректных файлов
+// возвращаются, а синтаксические ошибки попадают в Package.Diagnostics
+func WithPartialResults() Option {
+	return func(parser *Parser) {
+		parser.partial = true
+	}
+}
+
+// WithTracerProvider задает провайдер спанов OpenTelemetry (по умолчанию глобальный otel.GetTracerProvider())
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(parser *Parser) {
+		parser.tracerProvider = tracerProvider
+	}
+}
+
+// WithMeterProvider задает провайдер метрик OpenTelemetry (по умолчанию глобальный otel.GetMeterProvider())
+func WithMeterProvider(meterProvider metric.MeterProvider) Option {
+	return func(parser *Parser) {
+		parser.meterProvider = meterProvider
+	}
+}
+
+// WithOverlay подменяет содержимое файлов на диске при вызове ParsePackage.
+// Ключи — пути файлов (относительные пути отсчитываются от текущей директории).
+func WithOverlay(overlay map[string][]byte) Option {
+	return func(parser *Parser) {
+		parser.overlay = make(map[string][]byte, len(overlay))
+		for name, content := range overlay {
+			if absName, err := filepath.Abs(name); err == nil {
+				name = absName
+			}
+			parser.overlay[name] = content
+		}
+	}
+}
+
+// WithBuildContext задает GOOS, GOARCH и теги сборки для отбора файлов пакета, как это делает go build
+func WithBuildContext(buildContext build.Context) Option {
+	return func(parser *Parser) {
+		parser.buildContext = &buildContext
+	}
+}
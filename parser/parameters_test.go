@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+const parametersServiceFile = `package service
+
+import "context"
+
+// @asti name=UserService
+type UserService interface {
+	// @asti http-method=GET param.userID=path param.filter={source: query, required: false}
+	// @asti result.user.status=200 param.missing.source=body
+	// @http param.userID.name=id
+	Get(ctx context.Context, userID string, filter string) (user string, err error)
+	// @asti http-method=POST
+	Update(
+		ctx context.Context,
+		// @asti source=path
+		userID string,
+		// @http form=user
+		name, email string, // @asti source=body
+	) (err error)
+}
+`
+
+// TestParameterAnnotations проверяет аннотации параметров и результатов методов
+func TestParameterAnnotations(t *testing.T) {
+
+	result := parseService(t, parametersServiceFile, WithAnnotationNamespaces("http"), WithAnnotationInheritance(models.InheritanceRules{}))
+	if len(result.Interfaces) != 1 || len(result.Interfaces[0].Methods) != 2 {
+		t.Fatalf("Expected interface with two methods, got %+v", result.Interfaces)
+	}
+	get, update := result.Interfaces[0].Methods[0], result.Interfaces[0].Methods[1]
+	userID, filter := get.Parameters[1], get.Parameters[2].Annotations
+	position := filter[0].Value.Position
+	status, _ := get.Results[0].Annotations.Int("status")
+	_, restored := roundtrip(t, result)
+	restoredUserID := restored.Interfaces[0].Methods[0].Parameters[1]
+
+	expectations := []expectation{
+		{name: "ctx of Get", actual: fmt.Sprint(get.Parameters[0].Annotations == nil), expected: "true"},
+		{name: "userID param", actual: userID.Annotations.Get("param"), expected: "path"},
+		{name: "userID http name", actual: userID.Namespaces.Get("http", "name"), expected: "id"},
+		{name: "filter keys", actual: fmt.Sprint(filter.Keys()), expected: "[source required]"},
+		{name: "filter source", actual: filter.Get("source"), expected: "query"},
+		{name: "filter source position", actual: fmt.Sprintf("%d:%d", position.Line, position.Column), expected: "7:67"},
+		{name: "user result status", actual: fmt.Sprint(status), expected: "200"},
+		{name: "method keys of Get", actual: fmt.Sprint(get.Annotations.Keys()), expected: "[http-method]"},
+		{name: "method namespaces of Get", actual: fmt.Sprint(get.Namespaces == nil), expected: "true"},
+		{name: "effective param key of Get", actual: fmt.Sprint(get.Effective.Has("param.userID")), expected: "false"},
+		{name: "effective name of Get", actual: fmt.Sprint(get.Effective.Has("name")), expected: "true"},
+		{name: "leading comment on userID", actual: update.Parameters[1].Annotations.Get("source"), expected: "path"},
+		{name: "ctx and err of Update", actual: fmt.Sprint(update.Parameters[0].Annotations == nil && update.Results[0].Annotations == nil), expected: "true"},
+		{name: "diagnostics", actual: strings.Join(diagnosticLines(result), "\n"), expected: `service.go:8:55: warning: method Get has no parameter named missing for annotation param.missing.source [annotation-target]`},
+		{name: "userID param after roundtrip", actual: restoredUserID.Annotations.Get("param"), expected: "path"},
+		{name: "userID http name after roundtrip", actual: restoredUserID.Namespaces.Get("http", "name"), expected: "id"},
+	}
+	for _, variable := range update.Parameters[2:] {
+		expectations = append(expectations,
+			expectation{name: "trailing comment source on " + variable.Name, actual: variable.Annotations.Get("source"), expected: "body"},
+			expectation{name: "trailing comment http form on " + variable.Name, actual: variable.Namespaces.Get("http", "form"), expected: "user"},
+		)
+	}
+	checkExpectations(t, expectations)
+
+	update.Parameters[2].Annotations.Add("name", models.NewValue("name"))
+	if update.Parameters[3].Annotations.Has("name") {
+		t.Errorf("Expected variables of one field to have separate annotations, got %+v", update.Parameters[3].Annotations)
+	}
+}
+
+// TestParameterAnnotationsTypeChecking проверяет, что уточнение типов сохраняет аннотации параметров
+func TestParameterAnnotationsTypeChecking(t *testing.T) {
+
+	root := t.TempDir()
+	packageDir := filepath.Join(root, "service")
+	if err := os.MkdirAll(packageDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module github.com/test/parameters\n\ngo 1.24\n")
+	writeTestFile(t, filepath.Join(packageDir, "service.go"), parametersServiceFile)
+
+	result, err := NewParser(WithTypeChecking()).ParsePackage(context.Background(), packageDir)
+	if err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+	update := result.Interfaces[0].Methods[1]
+	if update.Parameters[1].Type != "string" || update.Parameters[1].Annotations.Get("source") != "path" {
+		t.Errorf("Expected type-checked userID with annotations, got %+v", update.Parameters[1])
+	}
+}
@@ -2,9 +2,18 @@ package parser
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"go/build"
+	"io/fs"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/seniorGolang/asti/parser/models"
 	"github.com/seniorGolang/asti/parser/pipeline"
@@ -12,9 +21,33 @@ import (
 
 const defaultAnnotationPrefix = "@asti"
 
+// Parser безопасен для одновременного использования из нескольких горутин:
+// состояние разбора пакета хранится отдельно для каждого вызова
 type Parser struct {
 	annotationPrefix string
 	annotationParser models.AnnotationParser
+	namespaces       []models.AnnotationNamespace
+	typeChecking     bool
+	partial          bool
+	overlay          map[string][]byte
+	buildContext     *build.Context
+	concurrency      int
+	cache            *packageCache
+	watchInterval    time.Duration
+	tracerProvider   trace.TracerProvider
+	meterProvider    metric.MeterProvider
+	stages           []pipeline.Stage
+	stageEdits       []stageEdit
+	customStages     bool
+	filterRules      []pipeline.FilterRule
+	validation       bool
+	validationRules  []pipeline.ValidationRule
+	schema           *models.AnnotationSchema
+	inheritance      *models.InheritanceRules
+	hooks            []pipeline.Hooks
+	middleware       []pipeline.Middleware
+	configErr        error
+	telemetry        *parserTelemetry
 	pipeline         *pipeline.Pipeline
 }
 
@@ -26,24 +59,20 @@ func NewParser(options ...Option) (parser *Parser) {
 	for _, apply := range options {
 		apply(parser)
 	}
-	annotationParser := models.NewAnnotationParser(parser.annotationPrefix)
-	parser.annotationParser = annotationParser
-	parser.pipeline = pipeline.NewPipeline(
-		pipeline.NewStageModule(),
-		pipeline.NewStageAST(annotationParser),
-		pipeline.NewStageFilter(),
-		pipeline.NewStageTypeCollection(annotationParser),
-		pipeline.NewStageSerialization(),
-	)
+	if parser.annotationParser == nil {
+		parser.annotationParser = models.NewAnnotationParser(parser.annotationPrefix)
+	}
+	parser.buildPipeline()
+	parser.telemetry = newParserTelemetry(parser.tracerProvider, parser.meterProvider)
 	return
 }
 
 // ParsePackage парсит пакет и возвращает информацию об интерфейсах
 func (p *Parser) ParsePackage(ctx context.Context, packagePath string) (result *models.Package, err error) {
 
-	if _, err = os.Stat(packagePath); os.IsNotExist(err) {
-		err = fmt.Errorf("package path does not exist: %s", packagePath)
-		return
+	var fileSystem pipeline.FileSystem
+	if len(p.overlay) > 0 {
+		fileSystem = pipeline.NewOverlayFileSystem(pipeline.OSFileSystem{}, p.overlay)
 	}
 	var absPath string
 	absPath, err = filepath.Abs(packagePath)
@@ -51,19 +80,134 @@ func (p *Parser) ParsePackage(ctx context.Context, packagePath string) (result *
 		err = fmt.Errorf("failed to get absolute path: %w", err)
 		return
 	}
+	result, err = p.traced(ctx, absPath, func(ctx context.Context) (result *models.Package, err error) {
+
+		if p.cache != nil {
+			result, err = p.parseCached(ctx, fileSystem, absPath)
+			return
+		}
+		result, err = p.parse(ctx, fileSystem, absPath)
+		return
+	})
+	return
+}
+
+// ParsePackages парсит несколько пакетов параллельно, не более WithConcurrency пакетов одновременно.
+// Результаты возвращаются в порядке путей; при первой ошибке оставшиеся пакеты не разбираются.
+func (p *Parser) ParsePackages(ctx context.Context, packagePaths ...string) (packages []*models.Package, err error) {
+
+	concurrency := p.concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	packages = make([]*models.Package, len(packagePaths))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(concurrency)
+	for i, packagePath := range packagePaths {
+		group.Go(func() (err error) {
+
+			if err = groupCtx.Err(); err != nil {
+				return
+			}
+			if packages[i], err = p.ParsePackage(groupCtx, packagePath); err != nil {
+				err = fmt.Errorf("failed to parse package %s: %w", packagePath, err)
+			}
+			return
+		})
+	}
+	if err = group.Wait(); err != nil {
+		packages = nil
+	}
+	return
+}
+
+// ParseFS парсит пакет из директории dir файловой системы fsys (например, embed.FS)
+func (p *Parser) ParseFS(ctx context.Context, fsys fs.FS, dir string) (result *models.Package, err error) {
+
+	packagePath := filepath.Clean(dir)
+	result, err = p.traced(ctx, packagePath, func(ctx context.Context) (result *models.Package, err error) {
+
+		result, err = p.parse(ctx, pipeline.NewFSFileSystem(fsys), packagePath)
+		return
+	})
+	return
+}
+
+// ParseSources парсит пакет из исходников в памяти: ключи — относительные пути файлов (в т.ч. go.mod)
+func (p *Parser) ParseSources(ctx context.Context, sources map[string][]byte) (result *models.Package, err error) {
+
+	packagePath := ""
+	for name := range sources {
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		dir := filepath.Dir(filepath.Clean(name))
+		if packagePath != "" && packagePath != dir {
+			err = fmt.Errorf("sources contain Go files from different directories: %s and %s", packagePath, dir)
+			return
+		}
+		packagePath = dir
+	}
+	if packagePath == "" {
+		err = fmt.Errorf("sources contain no Go files")
+		return
+	}
+	result, err = p.traced(ctx, packagePath, func(ctx context.Context) (result *models.Package, err error) {
+
+		result, err = p.parse(ctx, pipeline.NewOverlayFileSystem(nil, sources), packagePath)
+		return
+	})
+	return
+}
+
+func (p *Parser) parse(ctx context.Context, fileSystem pipeline.FileSystem, packagePath string) (result *models.Package, err error) {
+
+	statFileSystem := fileSystem
+	if statFileSystem == nil {
+		statFileSystem = pipeline.OSFileSystem{}
+	}
+	if _, err = statFileSystem.Stat(packagePath); errors.Is(err, fs.ErrNotExist) {
+		err = fmt.Errorf("package path does not exist: %s", packagePath)
+		return
+	}
+	buildContext := p.currentBuildContext()
 	initialData := pipeline.Data{
 		Package: &models.Package{
-			PackagePath: absPath,
+			PackagePath: packagePath,
+			Build: &models.BuildInfo{
+				GOOS:   buildContext.GOOS,
+				GOARCH: buildContext.GOARCH,
+				Tags:   buildContext.BuildTags,
+			},
 		},
+		FS:      fileSystem,
+		Build:   buildContext,
+		Partial: p.partial,
 	}
 
 	var resultData pipeline.Data
 	resultData, err = p.pipeline.Execute(ctx, initialData)
+	if resultData.Files != nil {
+		trace.SpanFromContext(ctx).SetAttributes(pipeline.AttributeFilesCount.Int(len(resultData.Files.Files)))
+	}
 	if err != nil {
 		err = fmt.Errorf("pipeline execution failed: %w", err)
 		return
 	}
-	result = resultData.Package
+	if result = resultData.Package; result == nil {
+		err = errors.New("pipeline execution failed: no package in result")
+	}
+	return
+}
+
+// currentBuildContext возвращает копию контекста сборки парсера, по умолчанию — build.Default
+func (p *Parser) currentBuildContext() (buildContext *build.Context) {
+
+	current := build.Default
+	if p.buildContext != nil {
+		current = *p.buildContext
+	}
+	buildContext = &current
 	return
 }
 
@@ -101,19 +245,36 @@ func (p *Parser) GetAnnotationPrefix() (prefix string) {
 	return
 }
 
+// addNamespace добавляет пространство имен аннотаций, заменяя ранее добавленное с тем же именем
+func (p *Parser) addNamespace(namespace models.AnnotationNamespace) {
+
+	for i := range p.namespaces {
+		if p.namespaces[i].Name == namespace.Name {
+			p.namespaces[i] = namespace
+			return
+		}
+	}
+	p.namespaces = append(p.namespaces, namespace)
+}
+
+// Namespaces возвращает имена дополнительных пространств имен аннотаций в порядке добавления
+func (p *Parser) Namespaces() (names []string) {
+
+	for _, namespace := range p.namespaces {
+		names = append(names, namespace.Name)
+	}
+	return
+}
+
+// SetAnnotationPrefix меняет префикс аннотаций и восстанавливает парсер аннотаций по умолчанию; нельзя вызывать одновременно с разбором пакетов
 func (p *Parser) SetAnnotationPrefix(prefix string) {
 
 	if prefix == "" {
-		prefix = "@asti"
+		prefix = defaultAnnotationPrefix
 	}
 
 	p.annotationPrefix = prefix
 	p.annotationParser = models.NewAnnotationParser(prefix)
 
-	p.pipeline = pipeline.NewPipeline(
-		pipeline.NewStageAST(p.annotationParser),
-		pipeline.NewStageFilter(),
-		pipeline.NewStageTypeCollection(p.annotationParser),
-		pipeline.NewStageSerialization(),
-	)
+	p.buildPipeline()
 }
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// partialSources пакет, в котором один файл сломан посередине редактирования
+var partialSources = map[string][]byte{
+	"go.mod":             []byte("module github.com/test/partial\n\ngo 1.24\n"),
+	"service/service.go": []byte(sourcesServiceFile),
+	"service/broken.go": []byte(`package service
+
+import "context"
+
+// @asti name=OrderService
+type OrderService interface {
+	// @asti method=GetOrder
+	GetOrder(ctx context.Context, id string) (order Order, err error)
+}
+
+type Order struct {
+	ID string
+}
+
+func helper() {
+	if true {
+		return
+`),
+	"service/header.go": []byte("/* unterminated build comment\n\npackage service\n"),
+}
+
+// TestPartialResults проверяет, что сломанный файл не лишает результата всего пакета
+func TestPartialResults(t *testing.T) {
+
+	if _, err := NewParser().ParseSources(context.Background(), partialSources); err == nil {
+		t.Fatal("Expected error without partial results mode, got nil")
+	}
+
+	result, err := NewParser(WithPartialResults()).ParseSources(context.Background(), partialSources)
+	if err != nil {
+		t.Fatalf("Failed to parse sources in partial mode: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, iface := range result.Interfaces {
+		names[iface.Name] = true
+	}
+	if !names["UserService"] {
+		t.Error("Interface UserService from healthy file not found")
+	}
+	if !names["OrderService"] {
+		t.Error("Interface OrderService before the syntax error not found")
+	}
+	for _, typeKey := range []string{"service.User", "service.Order"} {
+		if _, found := result.Types[typeKey]; !found {
+			t.Errorf("Type %s not found", typeKey)
+		}
+	}
+
+	var brokenFound, headerFound bool
+	for _, diagnostic := range result.Diagnostics {
+		if diagnostic.Code != models.CodeSyntax || diagnostic.Severity != models.SeverityError {
+			t.Errorf("Unexpected diagnostic %v", diagnostic)
+			continue
+		}
+		switch diagnostic.Position.File {
+		case "broken.go":
+			brokenFound = true
+			if diagnostic.Position.Line != 17 {
+				t.Errorf("Expected syntax error at broken.go:17, got %v", diagnostic)
+			}
+		case "header.go":
+			headerFound = true
+		}
+	}
+	if !brokenFound || !headerFound {
+		t.Errorf("Expected syntax diagnostics for broken.go and header.go, got %v", result.Diagnostics)
+	}
+	if !result.HasErrors() {
+		t.Error("Expected package to report errors")
+	}
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// expandPatterns раскрывает go-style шаблоны (./..., ./internal/...) в список директорий пакетов
+func expandPatterns(baseDir string, patterns []string) (dirs []string, err error) {
+
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		var matched []string
+		if matched, err = expandPattern(baseDir, pattern); err != nil {
+			return
+		}
+		for _, dir := range matched {
+			if !seen[dir] {
+				seen[dir] = true
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	sort.Strings(dirs)
+	return
+}
+
+func expandPattern(baseDir string, pattern string) (dirs []string, err error) {
+
+	pattern = filepath.ToSlash(pattern)
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.ToSlash(filepath.Join(baseDir, pattern))
+	}
+	idx := strings.Index(pattern, "...")
+	if idx == -1 {
+		dir := filepath.FromSlash(pattern)
+		var info os.FileInfo
+		if info, err = os.Stat(dir); err != nil {
+			err = fmt.Errorf("package path does not exist: %s", dir)
+			return
+		}
+		if !info.IsDir() {
+			err = fmt.Errorf("package path is not a directory: %s", dir)
+			return
+		}
+		dirs = append(dirs, dir)
+		return
+	}
+
+	// Обходим дерево начиная с последней директории перед первым "..."
+	prefix := pattern[:idx]
+	if !strings.HasSuffix(prefix, "/") {
+		prefix = path.Dir(prefix)
+	}
+	walkRoot := filepath.FromSlash(path.Clean(prefix))
+	match := matchPattern(pattern)
+	err = filepath.WalkDir(walkRoot, func(dir string, entry fs.DirEntry, walkErr error) (err error) {
+
+		if walkErr != nil {
+			err = walkErr
+			return
+		}
+		if !entry.IsDir() {
+			return
+		}
+		if dir != walkRoot {
+			name := entry.Name()
+			// Как и go build, пропускаем скрытые директории, testdata, vendor и вложенные модули
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" {
+				err = filepath.SkipDir
+				return
+			}
+			if _, statErr := os.Stat(filepath.Join(dir, "go.mod")); statErr == nil {
+				err = filepath.SkipDir
+				return
+			}
+		}
+		if !match(filepath.ToSlash(dir)) || !hasGoFiles(dir) {
+			return
+		}
+		dirs = append(dirs, dir)
+		return
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to expand pattern %s: %w", pattern, err)
+	}
+	return
+}
+
+// matchPattern возвращает функцию сопоставления пути с шаблоном по правилам go list
+func matchPattern(pattern string) (match func(name string) bool) {
+
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\.\.\.`, `.*`)
+	// Шаблон "x/..." совпадает и с самой директорией "x"
+	if strings.HasSuffix(expr, `/.*`) {
+		expr = strings.TrimSuffix(expr, `/.*`) + `(/.*)?`
+	}
+	re := regexp.MustCompile(`^` + expr + `$`)
+	match = re.MatchString
+	return
+}
+
+// hasGoFiles проверяет, содержит ли директория Go файлы, не являющиеся тестами
+func hasGoFiles(dir string) (has bool) {
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			has = true
+			return
+		}
+	}
+	return
+}
@@ -4,23 +4,37 @@ import (
 	"context"
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
 	"path/filepath"
-	"strings"
+	"slices"
 
 	"github.com/seniorGolang/asti/parser/models"
 )
 
 type StageAST struct {
-	annotationParser models.AnnotationParser
-	currentTypes     map[string]bool // типы, определенные в текущем файле
-	packageName      string          // имя текущего пакета
+	annotationReader
 }
 
-func NewStageAST(annotationParser models.AnnotationParser) (stage *StageAST) {
+// astExtraction состояние одного вызова StageAST.Process, благодаря которому этап
+// можно безопасно использовать из нескольких горутин
+type astExtraction struct {
+	*StageAST
+	currentTypes map[string]bool // типы, определенные в текущем пакете
+	packageName  string          // имя текущего пакета
+	diagnostics  []models.Diagnostic
+}
+
+// NewStageAST создает этап разбора интерфейсов. Аннотации annotationParser попадают в Annotations
+// элементов, аннотации дополнительных пространств имен namespaces — в Namespaces.
+func NewStageAST(annotationParser models.AnnotationParser, namespaces ...models.AnnotationNamespace) (stage *StageAST) {
+
+	stage = &StageAST{annotationReader: annotationReader{annotationParser: annotationParser, namespaces: namespaces}}
+	return
+}
+
+func (s *StageAST) Name() (name string) {
 
-	stage = &StageAST{annotationParser: annotationParser}
+	name = StageNameAST
 	return
 }
 
@@ -32,154 +46,81 @@ func (s *StageAST) Process(ctx context.Context, data Data) (result Data, err err
 		return
 	}
 	// Используем абсолютный путь для поиска файлов, если он доступен
-	packagePath := data.Package.PackagePath
-	if data.Annotations != nil {
-		if absPathData, exists := data.Annotations["_absolutePackagePath"]; exists {
-			if absPath, ok := absPathData["path"]; ok {
-				packagePath = absPath
-			}
-		}
-	}
+	packagePath := absolutePackagePath(data)
 
-	var files []string
-	files, err = filepath.Glob(filepath.Join(packagePath, "*.go"))
-	if err != nil {
-		err = fmt.Errorf("failed to find Go files: %w", err)
+	var files *PackageFiles
+	if files, err = packageFilesOf(ctx, &data, packagePath); err != nil {
 		return
 	}
-	
+
 	// Если нет Go файлов, возвращаем пустой результат
-	if len(files) == 0 {
+	if len(files.Files) == 0 {
 		// Для пустых директорий возвращаем пустой результат без ошибки
 		data.Interfaces = []models.Interface{}
-		data.Package.Annotations = make(models.Annotations)
+		data.Package.Annotations = models.Annotations{}
 		result = data
 		return
 	}
 
-	// Сначала собираем все типы из всех файлов пакета
-	s.currentTypes = make(map[string]bool)
-	fset := token.NewFileSet()
-	for _, file := range files {
-		if strings.HasSuffix(file, "_test.go") {
-			continue
-		}
-		var astFile *ast.File
-		astFile, err = parser.ParseFile(fset, file, nil, parser.ParseComments)
-		if err != nil {
-			err = fmt.Errorf("failed to parse file %s: %w", file, err)
-			return
-		}
-		// Собираем типы из текущего файла
-		for _, decl := range astFile.Decls {
-			if genDecl, ok := decl.(*ast.GenDecl); ok {
-				for _, spec := range genDecl.Specs {
-					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
-						s.currentTypes[typeSpec.Name.Name] = true
-					}
-				}
-			}
-		}
-		// Устанавливаем имя пакета из первого файла
-		if s.packageName == "" && astFile.Name != nil {
-			s.packageName = astFile.Name.Name
-		}
+	// Типы пакета берем из индекса объявлений, имя пакета — из первого файла
+	extraction := &astExtraction{
+		StageAST:     s,
+		currentTypes: make(map[string]bool, len(files.Types)),
+		packageName:  files.Name,
+	}
+	for typeName := range files.Types {
+		extraction.currentTypes[typeName] = true
 	}
 
 	var interfaces []models.Interface
 	var packageAnnotations models.Annotations
-	for _, file := range files {
-		if strings.HasSuffix(file, "_test.go") {
-			continue
-		}
-		var astFile *ast.File
-		astFile, err = parser.ParseFile(fset, file, nil, parser.ParseComments)
-		if err != nil {
-			err = fmt.Errorf("failed to parse file %s: %w", file, err)
+	var packageNamespaces models.Namespaces
+	for _, file := range files.Files {
+		if err = ctx.Err(); err != nil {
 			return
 		}
 		var fileInterfaces []models.Interface
 		var filePackageAnnotations models.Annotations
-		// Получаем имя модуля для формирования полного пути импорта
-		moduleName := ""
-		if data.Package != nil {
-			moduleName = data.Package.ModuleName
-		}
-		fileInterfaces, filePackageAnnotations, err = s.extractInterfaces(ctx, astFile, fset, file, packagePath, moduleName)
+		var filePackageNamespaces models.Namespaces
+		fileInterfaces, filePackageAnnotations, filePackageNamespaces, err = extraction.extractInterfaces(ctx, file.AST, files.Fset, file.Path, packagePath, data.Package.ImportPath())
 		if err != nil {
-			err = fmt.Errorf("failed to extract interfaces from %s: %w", file, err)
+			err = fmt.Errorf("failed to extract interfaces from %s: %w", file.Path, err)
 			return
 		}
 		interfaces = append(interfaces, fileInterfaces...)
 		if filePackageAnnotations != nil {
 			if packageAnnotations == nil {
-				packageAnnotations = make(models.Annotations)
-			}
-			for k, v := range filePackageAnnotations {
-				packageAnnotations[k] = v
+				packageAnnotations = models.Annotations{}
 			}
+			packageAnnotations.Merge(filePackageAnnotations)
 		}
+		for namespace, annotations := range filePackageNamespaces {
+			packageNamespaces.Merge(namespace, annotations)
+		}
+	}
+	for _, diagnostic := range extraction.diagnostics {
+		data.Report(diagnostic)
 	}
 	data.Interfaces = interfaces
 	data.Package.Annotations = packageAnnotations
+	data.Package.Namespaces = packageNamespaces
 	result = data
 	return
 }
 
-func (s *StageAST) hasAnnotation(commentText string) bool {
-
-	commentText = strings.TrimSpace(commentText)
-	if !strings.HasPrefix(commentText, "//") {
-		return false
-	}
-	content := strings.TrimSpace(strings.TrimPrefix(commentText, "//"))
-	if !strings.HasPrefix(content, "@") {
-		return false
-	}
-	prefix := strings.TrimPrefix(s.annotationParser.(*models.DefaultAnnotationParser).GetPrefix(), "@")
-	return strings.HasPrefix(content[1:], prefix)
-}
-
-func (s *StageAST) extractInterfaces(ctx context.Context, astFile *ast.File, fset *token.FileSet, filename string, packagePath string, moduleName string) (interfaces []models.Interface, packageAnnotations models.Annotations, err error) {
+func (s *astExtraction) extractInterfaces(ctx context.Context, astFile *ast.File, fset *token.FileSet, filename string, packagePath string, importPath string) (interfaces []models.Interface, packageAnnotations models.Annotations, packageNamespaces models.Namespaces, err error) {
 
-	packageAnnotations = make(models.Annotations)
-	if astFile.Doc != nil {
-		for _, comment := range astFile.Doc.List {
-			if s.hasAnnotation(comment.Text) {
-				var annotations models.Annotations
-				annotations, err = s.annotationParser.Parse(ctx, comment.Text)
-				if err != nil {
-					continue
-				}
-				for k, v := range annotations {
-					packageAnnotations[k] = v
-				}
-			}
-		}
-	}
+	packageAnnotations = models.Annotations{}
+	annotations, namespaces := s.read(ctx, astFile.Doc, fset, packagePath, &s.diagnostics)
+	packageAnnotations.Merge(annotations)
+	packageNamespaces = namespaces
 	for _, decl := range astFile.Decls {
 		if genDecl, ok := decl.(*ast.GenDecl); ok {
 			for _, spec := range genDecl.Specs {
 				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
 					if interfaceType, ok := typeSpec.Type.(*ast.InterfaceType); ok {
-						var interfaceAnnotations models.Annotations
-						if genDecl.Doc != nil {
-							for _, comment := range genDecl.Doc.List {
-								if s.hasAnnotation(comment.Text) {
-									var annotations models.Annotations
-									annotations, err = s.annotationParser.Parse(ctx, comment.Text)
-									if err == nil {
-										if interfaceAnnotations == nil {
-											interfaceAnnotations = make(models.Annotations)
-										}
-										for k, v := range annotations {
-											interfaceAnnotations[k] = v
-										}
-									}
-								}
-							}
-						}
-						if len(interfaceAnnotations) > 0 {
+						interfaceAnnotations, interfaceNamespaces := s.read(ctx, genDecl.Doc, fset, packagePath, &s.diagnostics)
+						if len(interfaceAnnotations) > 0 || len(interfaceNamespaces) > 0 {
 							pos := fset.Position(typeSpec.Pos())
 							var relativePath string
 							relativePath, err = filepath.Rel(packagePath, filename)
@@ -187,29 +128,12 @@ func (s *StageAST) extractInterfaces(ctx context.Context, astFile *ast.File, fse
 								relativePath = filename
 							}
 
-							// Определяем путь импорта для текущего пакета
-							importPath := packagePath
-							// Если это абсолютный путь, извлекаем относительный путь от корня модуля
-							if filepath.IsAbs(importPath) {
-								// Используем общую функцию для получения информации о модуле
-								if modPath, err := FindModuleRoot(importPath); err == nil {
-									if relPath, err := filepath.Rel(modPath, importPath); err == nil {
-										importPath = relPath
-									}
-								}
-							}
-
-							// Формируем полный путь импорта с именем модуля
-							fullImportPath := importPath
-							if moduleName != "" {
-								fullImportPath = moduleName + "/" + importPath
-							}
-
 							iface := models.Interface{
 								Name:        typeSpec.Name.Name,
 								Package:     astFile.Name.Name,
-								Import:      fullImportPath,
+								Import:      importPath,
 								Annotations: interfaceAnnotations,
+								Namespaces:  interfaceNamespaces,
 								Position: models.Position{
 									File:   relativePath,
 									Line:   pos.Line,
@@ -219,7 +143,7 @@ func (s *StageAST) extractInterfaces(ctx context.Context, astFile *ast.File, fse
 							}
 
 							var methods []models.Method
-							methods, err = s.extractMethods(ctx, interfaceType, fset, filename, packagePath)
+							methods, err = s.extractMethods(ctx, interfaceType, astFile.Comments, fset, filename, packagePath)
 							if err != nil {
 								err = fmt.Errorf("failed to extract methods: %w", err)
 								return
@@ -236,29 +160,30 @@ func (s *StageAST) extractInterfaces(ctx context.Context, astFile *ast.File, fse
 	return
 }
 
-func (s *StageAST) extractMethods(ctx context.Context, interfaceType *ast.InterfaceType, fset *token.FileSet, filename string, packagePath string) (methods []models.Method, err error) {
+func (s *astExtraction) extractMethods(ctx context.Context, interfaceType *ast.InterfaceType, comments []*ast.CommentGroup, fset *token.FileSet, filename string, packagePath string) (methods []models.Method, err error) {
 
 	methodAnnotations := make(map[string]models.Annotations)
+	methodNamespaces := make(map[string]models.Namespaces)
 	for _, field := range interfaceType.Methods.List {
 		if _, ok := field.Type.(*ast.FuncType); ok {
 			if len(field.Names) > 0 {
 				methodName := field.Names[0].Name
 
-				if field.Doc != nil {
-					for _, comment := range field.Doc.List {
-						if s.hasAnnotation(comment.Text) {
-							var annotations models.Annotations
-							annotations, err = s.annotationParser.Parse(ctx, comment.Text)
-							if err == nil {
-								if methodAnnotations[methodName] == nil {
-									methodAnnotations[methodName] = make(models.Annotations)
-								}
-								for k, v := range annotations {
-									methodAnnotations[methodName][k] = v
-								}
-							}
-						}
+				annotations, namespaces := s.read(ctx, field.Doc, fset, packagePath, &s.diagnostics)
+				if annotations != nil {
+					merged := methodAnnotations[methodName]
+					if merged == nil {
+						merged = models.Annotations{}
+					}
+					merged.Merge(annotations)
+					methodAnnotations[methodName] = merged
+				}
+				if namespaces != nil {
+					merged := methodNamespaces[methodName]
+					for namespace, namespaceAnnotations := range namespaces {
+						merged.Merge(namespace, namespaceAnnotations)
 					}
+					methodNamespaces[methodName] = merged
 				}
 			}
 		}
@@ -284,12 +209,13 @@ func (s *StageAST) extractMethods(ctx context.Context, interfaceType *ast.Interf
 							Column: pos.Column,
 						},
 						Annotations: methodAnnotations[methodName],
+						Namespaces:  methodNamespaces[methodName],
 					},
 					ID: methodName,
 				}
 
 				var parameters []models.Variable
-				parameters, err = s.extractVariables(funcType.Params)
+				parameters, err = s.extractVariables(ctx, funcType.Params, comments, fset, packagePath)
 				if err != nil {
 					err = fmt.Errorf("failed to extract parameters: %w", err)
 					return
@@ -297,12 +223,13 @@ func (s *StageAST) extractMethods(ctx context.Context, interfaceType *ast.Interf
 				method.Parameters = parameters
 
 				var results []models.Variable
-				results, err = s.extractVariables(funcType.Results)
+				results, err = s.extractVariables(ctx, funcType.Results, comments, fset, packagePath)
 				if err != nil {
 					err = fmt.Errorf("failed to extract results: %w", err)
 					return
 				}
 				method.Results = results
+				attachVariableAnnotations(&method, &s.diagnostics)
 
 				methods = append(methods, method)
 			}
@@ -311,26 +238,33 @@ func (s *StageAST) extractMethods(ctx context.Context, interfaceType *ast.Interf
 	return
 }
 
-func (s *StageAST) extractVariables(fieldList *ast.FieldList) (variables []models.Variable, err error) {
+func (s *astExtraction) extractVariables(ctx context.Context, fieldList *ast.FieldList, comments []*ast.CommentGroup, fset *token.FileSet, packagePath string) (variables []models.Variable, err error) {
 
 	if fieldList == nil {
 		return
 	}
+	groups := fieldComments(fieldList, comments, fset)
 	for _, field := range fieldList.List {
 		typeStr := s.typeToString(field.Type)
+		annotations, namespaces := s.readVariableComments(ctx, groups[field], fset, packagePath, &s.diagnostics)
 		if len(field.Names) > 0 {
 			for _, name := range field.Names {
+				// Каждое имя получает свою копию аннотаций: a, b string // @asti x
 				variable := models.Variable{
-					Name: name.Name,
-					Type: typeStr,
+					Name:        name.Name,
+					Type:        typeStr,
+					Annotations: slices.Clone(annotations),
+					Namespaces:  cloneNamespaces(namespaces),
 				}
 				s.analyzeTypeCharacteristics(field.Type, &variable)
 				variables = append(variables, variable)
 			}
 		} else {
 			variable := models.Variable{
-				Name: "",
-				Type: typeStr,
+				Name:        "",
+				Type:        typeStr,
+				Annotations: annotations,
+				Namespaces:  namespaces,
 			}
 			s.analyzeTypeCharacteristics(field.Type, &variable)
 			variables = append(variables, variable)
@@ -339,7 +273,7 @@ func (s *StageAST) extractVariables(fieldList *ast.FieldList) (variables []model
 	return
 }
 
-func (s *StageAST) typeToString(expr ast.Expr) (typeStr string) {
+func (s *astExtraction) typeToString(expr ast.Expr) (typeStr string) {
 
 	switch t := expr.(type) {
 	case *ast.Ident:
@@ -0,0 +1,142 @@
+package pipeline
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// annotationReader разбирает аннотации основного парсера и дополнительных пространств имен
+type annotationReader struct {
+	annotationParser models.AnnotationParser
+	namespaces       []models.AnnotationNamespace
+}
+
+// read разбирает аннотации группы комментариев. Если аннотаций нет, annotations и namespaces равны nil.
+func (r annotationReader) read(ctx context.Context, group *ast.CommentGroup, fset *token.FileSet, packagePath string, diagnostics *[]models.Diagnostic) (annotations models.Annotations, namespaces models.Namespaces) {
+
+	if group == nil {
+		return
+	}
+	for _, block := range annotationBlocks(group, annotationMatcher(ctx, r.annotationParser)) {
+		if parsed, ok := parseAnnotation(ctx, r.annotationParser, fset, block, packagePath, diagnostics); ok {
+			if annotations == nil {
+				annotations = models.Annotations{}
+			}
+			annotations.Merge(parsed)
+		}
+	}
+	for _, namespace := range r.namespaces {
+		for _, block := range annotationBlocks(group, annotationMatcher(ctx, namespace.Parser)) {
+			if parsed, ok := parseAnnotation(ctx, namespace.Parser, fset, block, packagePath, diagnostics); ok {
+				namespaces.Merge(namespace.Name, parsed)
+			}
+		}
+	}
+	return
+}
+
+// annotationMatcher возвращает проверку строки комментария: Match, если парсер реализует
+// models.AnnotationMatcher, иначе пробный разбор строки
+func annotationMatcher(ctx context.Context, annotationParser models.AnnotationParser) (match func(commentText string) bool) {
+
+	if matcher, ok := annotationParser.(models.AnnotationMatcher); ok {
+		match = matcher.Match
+		return
+	}
+	match = func(commentText string) bool {
+		annotations, err := annotationParser.Parse(ctx, commentText)
+		return err != nil || len(annotations) > 0
+	}
+	return
+}
+
+// annotationBlocks группирует комментарии группы в аннотации: строка // с префиксом вместе
+// со строками продолжения или блочный комментарий /* */ с префиксом
+func annotationBlocks(group *ast.CommentGroup, hasAnnotation func(commentText string) bool) (blocks [][]*ast.Comment) {
+
+	if group == nil {
+		return
+	}
+	var current []*ast.Comment
+	for _, comment := range group.List {
+		if current != nil && isContinuation(current, comment) {
+			current = append(current, comment)
+			continue
+		}
+		if current != nil {
+			blocks = append(blocks, current)
+			current = nil
+		}
+		if !hasAnnotation(comment.Text) {
+			continue
+		}
+		if strings.HasPrefix(comment.Text, "/*") {
+			blocks = append(blocks, []*ast.Comment{comment})
+			continue
+		}
+		current = []*ast.Comment{comment}
+	}
+	if current != nil {
+		blocks = append(blocks, current)
+	}
+	return
+}
+
+// isContinuation сообщает, продолжает ли строка comment аннотацию block: предыдущая строка
+// заканчивается на \, в аннотации не закрыта кавычка или скобка, либо строка с дополнительным
+// отступом начинается с пары ключ=значение. Отступ без ключ=значение (блок кода в документации)
+// аннотацию не продолжает.
+func isContinuation(block []*ast.Comment, comment *ast.Comment) (continues bool) {
+
+	if !strings.HasPrefix(comment.Text, "//") {
+		return
+	}
+	texts := make([]string, 0, len(block))
+	for _, line := range block {
+		texts = append(texts, line.Text)
+	}
+	previous := strings.TrimRight(block[len(block)-1].Text, " \t")
+	if strings.HasSuffix(previous, `\`) || models.Unfinished(strings.Join(texts, "\n")) {
+		continues = true
+		return
+	}
+	content := strings.TrimPrefix(comment.Text, "//")
+	indented := strings.HasPrefix(content, "  ") || strings.HasPrefix(content, "\t") || strings.HasPrefix(content, " \t")
+	continues = indented && isKeyValue(content)
+	return
+}
+
+// isKeyValue сообщает, начинается ли текст с пары ключ=значение
+func isKeyValue(content string) (keyValue bool) {
+
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return
+	}
+	key, _, found := strings.Cut(fields[0], "=")
+	keyValue = found && key != "" && !strings.ContainsAny(key, "\"'`")
+	return
+}
+
+// blockText собирает текст аннотации из комментариев block. Строки после первой дополняются
+// пробелами до колонки комментария, чтобы позиции в тексте совпадали с колонками исходного файла.
+func blockText(fset *token.FileSet, block []*ast.Comment) (text string) {
+
+	var builder strings.Builder
+	line := fset.Position(block[0].Pos()).Line
+	for i, comment := range block {
+		position := fset.Position(comment.Pos())
+		if i > 0 {
+			builder.WriteString(strings.Repeat("\n", max(position.Line-line, 1)))
+			builder.WriteString(strings.Repeat(" ", position.Column-1))
+		}
+		builder.WriteString(comment.Text)
+		line = position.Line + strings.Count(comment.Text, "\n")
+	}
+	text = builder.String()
+	return
+}
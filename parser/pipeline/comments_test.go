@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"context"
+	"go/ast"
+	"reflect"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// TestAnnotationBlocks проверяет, какие строки комментария продолжают аннотацию
+func TestAnnotationBlocks(t *testing.T) {
+
+	match := annotationMatcher(context.Background(), models.NewAnnotationParser("@asti"))
+	for _, test := range []struct {
+		name     string
+		lines    []string
+		expected [][]string
+	}{
+		{
+			name:     "backslash",
+			lines:    []string{`// @asti name=UserService \`, "// version=2"},
+			expected: [][]string{{`// @asti name=UserService \`, "// version=2"}},
+		},
+		{
+			name:     "open quote",
+			lines:    []string{`// @asti description="Manages users`, `// and profiles"`, "// Plain text"},
+			expected: [][]string{{`// @asti description="Manages users`, `// and profiles"`}},
+		},
+		{
+			name:     "open bracket",
+			lines:    []string{"// @asti params=[id,", "// name]"},
+			expected: [][]string{{"// @asti params=[id,", "// name]"}},
+		},
+		{
+			name:     "indented key value",
+			lines:    []string{"// @asti method=Find", "//     http.method=GET"},
+			expected: [][]string{{"// @asti method=Find", "//     http.method=GET"}},
+		},
+		{
+			name:     "doc code block",
+			lines:    []string{"// @asti a=1", "//", "//	Example code block", "//	x = 1"},
+			expected: [][]string{{"// @asti a=1"}},
+		},
+		{
+			name:     "directive backslash",
+			lines:    []string{`//asti:http method=GET \`, "//   path=/users"},
+			expected: [][]string{{`//asti:http method=GET \`, "//   path=/users"}},
+		},
+		{
+			name:     "apostrophe",
+			lines:    []string{"// @asti summary=Don't retry", "// Plain text"},
+			expected: [][]string{{"// @asti summary=Don't retry"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			group := &ast.CommentGroup{}
+			for _, line := range test.lines {
+				group.List = append(group.List, &ast.Comment{Text: line})
+			}
+			var actual [][]string
+			for _, block := range annotationBlocks(group, match) {
+				var texts []string
+				for _, comment := range block {
+					texts = append(texts, comment.Text)
+				}
+				actual = append(actual, texts)
+			}
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Expected blocks %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
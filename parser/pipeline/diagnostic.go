@@ -0,0 +1,55 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// positionOf возвращает позицию в коде с путем файла относительно директории пакета
+func positionOf(fset *token.FileSet, pos token.Pos, packagePath string) (position models.Position) {
+
+	filePosition := fset.Position(pos)
+	position = models.Position{
+		File:   filePosition.Filename,
+		Line:   filePosition.Line,
+		Column: filePosition.Column,
+	}
+	if relativePath, err := filepath.Rel(packagePath, filePosition.Filename); err == nil {
+		position.File = relativePath
+	}
+	return
+}
+
+// parseAnnotation разбирает аннотацию из комментариев block (строка с префиксом и строки продолжения),
+// ошибка разбора сохраняется в diagnostics
+func parseAnnotation(ctx context.Context, annotationParser models.AnnotationParser, fset *token.FileSet, block []*ast.Comment, packagePath string, diagnostics *[]models.Diagnostic) (annotations models.Annotations, ok bool) {
+
+	base := positionOf(fset, block[0].Pos(), packagePath)
+	var err error
+	if annotations, err = annotationParser.Parse(ctx, blockText(fset, block)); err != nil {
+		diagnostic := models.Diagnostic{
+			Severity: models.SeverityError,
+			Code:     models.CodeAnnotationSyntax,
+			Message:  fmt.Sprintf("invalid annotation: %v", err),
+			Position: base,
+		}
+		// Ошибка синтаксиса указывает на место в тексте аннотации
+		var syntaxErr *models.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			syntaxErr = syntaxErr.WithBase(base)
+			diagnostic.Message = "invalid annotation: " + syntaxErr.Message
+			diagnostic.Position = syntaxErr.Position
+		}
+		*diagnostics = append(*diagnostics, diagnostic)
+		return
+	}
+	annotations = annotations.WithBase(base)
+	ok = true
+	return
+}
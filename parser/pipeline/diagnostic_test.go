@@ -0,0 +1,218 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// failingAnnotationParser возвращает ошибку для аннотаций, содержащих "broken"
+type failingAnnotationParser struct {
+	*models.DefaultAnnotationParser
+}
+
+func (p failingAnnotationParser) Parse(ctx context.Context, text string) (annotations models.Annotations, err error) {
+
+	if strings.Contains(text, "broken") {
+		err = fmt.Errorf("unexpected token")
+		return
+	}
+	annotations, err = p.DefaultAnnotationParser.Parse(ctx, text)
+	return
+}
+
+const diagnosticServiceFile = `package service
+
+import "context"
+
+// @asti name=UserService
+// @asti broken
+type UserService interface {
+	// @asti broken
+	GetUser(ctx context.Context, id string) (user User, err error)
+}
+
+type User struct {
+	ID string
+}
+`
+
+// TestDiagnosticsAnnotationErrors проверяет, что ошибки разбора аннотаций становятся диагностиками с позицией
+func TestDiagnosticsAnnotationErrors(t *testing.T) {
+
+	fset := token.NewFileSet()
+	filename := filepath.Join("/src", "service", "service.go")
+	astFile, err := parser.ParseFile(fset, filename, diagnosticServiceFile, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+
+	annotationParser := failingAnnotationParser{models.NewAnnotationParser("@asti")}
+	var diagnostics []models.Diagnostic
+	var parsed int
+	for _, group := range astFile.Comments {
+		for _, comment := range group.List {
+			if _, ok := parseAnnotation(context.Background(), annotationParser, fset, []*ast.Comment{comment}, filepath.Dir(filename), &diagnostics); ok {
+				parsed++
+			}
+		}
+	}
+	if parsed != 1 || len(diagnostics) != 2 {
+		t.Fatalf("Expected 1 parsed annotation and 2 diagnostics, got %d and %v", parsed, diagnostics)
+	}
+	expectedLines := []int{6, 8}
+	for i, diagnostic := range diagnostics {
+		if diagnostic.Severity != models.SeverityError || diagnostic.Code != models.CodeAnnotationSyntax {
+			t.Errorf("Unexpected diagnostic %v", diagnostic)
+		}
+		if diagnostic.Position.File != "service.go" || diagnostic.Position.Line != expectedLines[i] {
+			t.Errorf("Expected diagnostic at service.go:%d, got %v", expectedLines[i], diagnostic)
+		}
+	}
+	if text := diagnostics[0].String(); text != "service.go:6:1: error: invalid annotation: unexpected token [annotation-syntax]" {
+		t.Errorf("Unexpected diagnostic text %q", text)
+	}
+
+	// Одна и та же аннотация, прочитанная разными этапами, сообщается один раз
+	var data Data
+	for _, diagnostic := range append(diagnostics, diagnostics...) {
+		data.Report(diagnostic)
+	}
+	if len(data.Diagnostics) != 2 {
+		t.Errorf("Expected duplicate diagnostics to be dropped, got %v", data.Diagnostics)
+	}
+	pkg := &models.Package{Diagnostics: data.Diagnostics}
+	if !pkg.HasErrors() {
+		t.Error("Expected package to report errors")
+	}
+}
+
+// TestDiagnosticsAnnotationSyntax проверяет позиции синтаксических ошибок аннотаций в исходном файле
+func TestDiagnosticsAnnotationSyntax(t *testing.T) {
+
+	result, err := NewPipeline(NewStageModule(), NewStageAST(models.NewAnnotationParser("@asti"))).Execute(context.Background(), Data{
+		Package: &models.Package{PackagePath: "service"},
+		FS: NewOverlayFileSystem(nil, map[string][]byte{
+			"go.mod": []byte("module github.com/test/syntax\n\ngo 1.24\n"),
+			"service/service.go": []byte(`package service
+
+import "context"
+
+// @asti name=Service
+type Service interface {
+	// @asti method=Get title="abc
+	Get(ctx context.Context) (err error)
+	// @asti method=Broken params=[id, name
+	Broken(ctx context.Context) (err error)
+}
+`),
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Pipeline execution failed: %v", err)
+	}
+	expected := []string{
+		`service.go:7:28: error: invalid annotation: unterminated string [annotation-syntax]`,
+		`service.go:9:32: error: invalid annotation: unclosed "[" [annotation-syntax]`,
+	}
+	var actual []string
+	for _, diagnostic := range result.Diagnostics {
+		actual = append(actual, diagnostic.String())
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected diagnostics:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+// TestDiagnosticsModuleNotFound проверяет предупреждение об отсутствии go.mod
+func TestDiagnosticsModuleNotFound(t *testing.T) {
+
+	annotationParser := models.NewAnnotationParser("@asti")
+	result, err := NewPipeline(NewStageModule(), NewStageAST(annotationParser)).Execute(context.Background(), Data{
+		Package: &models.Package{PackagePath: "service"},
+		FS: NewOverlayFileSystem(nil, map[string][]byte{
+			"service/service.go": []byte(diagnosticServiceFile),
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Pipeline execution failed: %v", err)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Severity != models.SeverityWarning || result.Diagnostics[0].Code != models.CodeModuleNotFound {
+		t.Errorf("Expected module-not-found warning, got %v", result.Diagnostics)
+	}
+}
+
+// TestDiagnosticsValidation проверяет, что нарушения правил валидации собираются для всех интерфейсов
+func TestDiagnosticsValidation(t *testing.T) {
+
+	position := models.Position{File: "service.go", Line: 3, Column: 6}
+	result, err := NewStageValidation().Process(context.Background(), Data{
+		Interfaces: []models.Interface{
+			{Name: "NoAnnotations", Position: position},
+			{Name: "Valid", Annotations: models.Annotations{{Key: "name", Value: models.NewValue("Valid")}}},
+			{Name: "NoContext", Annotations: models.Annotations{{Key: "name", Value: models.NewValue("NoContext")}}, Methods: []models.Method{{ID: "Run"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+	if len(result.Interfaces) != 1 || result.Interfaces[0].Name != "Valid" {
+		t.Errorf("Expected only Valid interface, got %+v", result.Interfaces)
+	}
+	if len(result.Diagnostics) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %v", result.Diagnostics)
+	}
+	if result.Diagnostics[0].Code != models.CodeValidation || result.Diagnostics[0].Position != position {
+		t.Errorf("Unexpected validation diagnostic %v", result.Diagnostics[0])
+	}
+}
+
+// TestDiagnosticsTypeCheck проверяет, что ошибки go/types возвращаются диагностиками, а не прерывают разбор
+func TestDiagnosticsTypeCheck(t *testing.T) {
+
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":             "module github.com/test/typeerrors\n\ngo 1.24\n",
+		"service/service.go": strings.Replace(diagnosticServiceFile, "ID string", "ID Missing", 1),
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	annotationParser := models.NewAnnotationParser("@asti")
+	result, err := NewPipeline(
+		NewStageModule(),
+		NewStageAST(annotationParser),
+		NewStageTypeCollection(annotationParser),
+		NewStageTypeCheck(),
+	).Execute(context.Background(), Data{
+		Package: &models.Package{PackagePath: filepath.Join(tempDir, "service")},
+	})
+	if err != nil {
+		t.Fatalf("Pipeline execution failed: %v", err)
+	}
+	if len(result.Interfaces) != 1 {
+		t.Errorf("Expected syntactic result to be kept, got %d interfaces", len(result.Interfaces))
+	}
+	if len(result.Diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %v", result.Diagnostics)
+	}
+	diagnostic := result.Diagnostics[0]
+	if diagnostic.Code != models.CodeTypeCheck || diagnostic.Position.File != "service.go" || diagnostic.Position.Line != 13 {
+		t.Errorf("Unexpected type check diagnostic %v", diagnostic)
+	}
+}
@@ -0,0 +1,183 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// SourceFile разобранный Go файл пакета
+type SourceFile struct {
+	Path string
+	AST  *ast.File
+}
+
+// TypeDecl объявление типа в одном из файлов пакета
+type TypeDecl struct {
+	File    *SourceFile
+	GenDecl *ast.GenDecl
+	Spec    *ast.TypeSpec
+}
+
+// PackageFiles файлы пакета, разобранные один раз и общие для всех этапов pipeline
+type PackageFiles struct {
+	Dir   string
+	Name  string
+	Fset  *token.FileSet
+	Files []*SourceFile
+	// Types индекс объявлений типов пакета по имени
+	Types map[string]TypeDecl
+	// Diagnostics синтаксические ошибки файлов, найденные в режиме частичного результата
+	Diagnostics []models.Diagnostic
+}
+
+// LookupType ищет объявление типа пакета по имени
+func (f *PackageFiles) LookupType(name string) (decl TypeDecl, found bool) {
+
+	decl, found = f.Types[name]
+	return
+}
+
+// loadPackageFiles отбирает файлы пакета по контексту сборки и парсит каждый из них ровно один раз.
+// В режиме частичного результата (Data.Partial) файлы с синтаксическими ошибками разбираются
+// с восстановлением, а ошибки сохраняются в Diagnostics вместо прерывания разбора.
+func loadPackageFiles(ctx context.Context, data Data, dir string) (files *PackageFiles, err error) {
+
+	fileSystem := fileSystemOf(data)
+	files = &PackageFiles{
+		Dir:   dir,
+		Fset:  token.NewFileSet(),
+		Types: make(map[string]TypeDecl),
+	}
+	var paths []string
+	if data.Partial {
+		paths = selectGoFilesPartial(fileSystem, buildContextOf(data), dir, files)
+	} else if paths, err = selectGoFiles(fileSystem, buildContextOf(data), dir); err != nil {
+		err = fmt.Errorf("failed to find Go files: %w", err)
+		return
+	}
+	mode := parser.ParseComments
+	if data.Partial {
+		mode |= parser.AllErrors
+	}
+	for _, path := range paths {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		var astFile *ast.File
+		if astFile, err = parseFile(files.Fset, fileSystem, path, mode); err != nil {
+			if !data.Partial {
+				err = fmt.Errorf("failed to parse file %s: %w", path, err)
+				return
+			}
+			files.reportSyntaxErrors(path, err)
+			err = nil
+			// Файл без распознанного заголовка пакета обработать нельзя
+			if astFile == nil || astFile.Name == nil || astFile.Name.Name == "_" {
+				continue
+			}
+		}
+		sourceFile := &SourceFile{Path: path, AST: astFile}
+		files.Files = append(files.Files, sourceFile)
+		if files.Name == "" && astFile.Name != nil {
+			files.Name = astFile.Name.Name
+		}
+		for _, decl := range astFile.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					if _, exists := files.Types[typeSpec.Name.Name]; !exists {
+						files.Types[typeSpec.Name.Name] = TypeDecl{File: sourceFile, GenDecl: genDecl, Spec: typeSpec}
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// selectGoFilesPartial отбирает файлы пакета, пропуская с диагностикой файлы с нечитаемым заголовком
+func selectGoFilesPartial(fileSystem FileSystem, buildContext *build.Context, dir string, files *PackageFiles) (paths []string) {
+
+	candidates, err := listGoFiles(fileSystem, dir)
+	if err != nil {
+		files.report(models.Position{}, err.Error())
+		return
+	}
+	for _, candidate := range candidates {
+		var match bool
+		if match, err = matchGoFile(fileSystem, buildContext, candidate); err != nil {
+			files.report(models.Position{File: files.relativePath(candidate)}, err.Error())
+			continue
+		}
+		if match {
+			paths = append(paths, candidate)
+		}
+	}
+	return
+}
+
+// reportSyntaxErrors превращает ошибки go/parser в диагностики с позициями
+func (f *PackageFiles) reportSyntaxErrors(path string, err error) {
+
+	var errorList scanner.ErrorList
+	if !errors.As(err, &errorList) {
+		f.report(models.Position{File: f.relativePath(path)}, err.Error())
+		return
+	}
+	for _, syntaxErr := range errorList {
+		f.report(models.Position{
+			File:   f.relativePath(syntaxErr.Pos.Filename),
+			Line:   syntaxErr.Pos.Line,
+			Column: syntaxErr.Pos.Column,
+		}, syntaxErr.Msg)
+	}
+}
+
+func (f *PackageFiles) report(position models.Position, message string) {
+
+	f.Diagnostics = append(f.Diagnostics, models.Diagnostic{
+		Severity: models.SeverityError,
+		Code:     models.CodeSyntax,
+		Message:  message,
+		Position: position,
+	})
+}
+
+func (f *PackageFiles) relativePath(path string) (relativePath string) {
+
+	relativePath = path
+	if rel, err := filepath.Rel(f.Dir, path); err == nil {
+		relativePath = rel
+	}
+	return
+}
+
+// packageFilesOf возвращает разобранные файлы пакета из data, загружая их при первом обращении
+func packageFilesOf(ctx context.Context, data *Data, dir string) (files *PackageFiles, err error) {
+
+	if data.Files != nil && data.Files.Dir == dir {
+		files = data.Files
+		return
+	}
+	if files, err = loadPackageFiles(ctx, *data, dir); err != nil {
+		return
+	}
+	for _, diagnostic := range files.Diagnostics {
+		data.Report(diagnostic)
+	}
+	data.Files = files
+	SetValue(data, KeyFileSet, files.Fset)
+	return
+}
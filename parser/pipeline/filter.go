@@ -24,6 +24,12 @@ func NewStageFilter(rules ...FilterRule) (stage *StageFilter) {
 	return
 }
 
+func (s *StageFilter) Name() (name string) {
+
+	name = StageNameFilter
+	return
+}
+
 // Process выполняет фильтрацию интерфейсов
 func (s *StageFilter) Process(ctx context.Context, data Data) (result Data, err error) {
 
@@ -52,7 +58,7 @@ type FilterRule interface {
 type AnnotationFilterRule struct{}
 
 func (r *AnnotationFilterRule) ShouldInclude(iface models.Interface) (shouldInclude bool) {
-	shouldInclude = len(iface.Annotations) > 0
+	shouldInclude = len(iface.Annotations) > 0 || len(iface.Namespaces) > 0
 	return
 }
 
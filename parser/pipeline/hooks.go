@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSkipRemaining возвращается хуком BeforeStage или AfterStage, чтобы завершить pipeline
+// без ошибки: оставшиеся этапы не выполняются, результатом становятся текущие данные
+var ErrSkipRemaining = errors.New("skip remaining stages")
+
+// StageEvent описание выполнения этапа, передаваемое хукам.
+// Input и Output — копии Data, разделяющие со следующими этапами слайсы и карты.
+type StageEvent struct {
+	Stage    string
+	Index    int
+	Input    Data
+	Output   Data
+	Started  time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// Hooks обработчики жизненного цикла этапов; любое поле может быть nil.
+// BeforeStage и AfterStage могут прервать pipeline ошибкой или ErrSkipRemaining.
+// OnError получает ошибку этапа и возвращает ошибку pipeline: nil означает, что ошибка
+// обработана и pipeline продолжается с данными Output, а если этап не вернул данных
+// (Output.Package равен nil) — с данными Input.
+type Hooks struct {
+	BeforeStage func(ctx context.Context, event StageEvent) (err error)
+	AfterStage  func(ctx context.Context, event StageEvent) (err error)
+	OnError     func(ctx context.Context, event StageEvent) (err error)
+}
+
+// Middleware оборачивает выполнение этапа с именем name
+type Middleware func(name string, next Stage) (stage Stage)
+
+// AddHooks добавляет обработчики жизненного цикла этапов; вызываются в порядке добавления
+func (p *Pipeline) AddHooks(hooks ...Hooks) {
+
+	p.hooks = append(p.hooks, hooks...)
+}
+
+// Use добавляет middleware этапов; первое добавленное middleware выполняется снаружи остальных
+func (p *Pipeline) Use(middleware ...Middleware) {
+
+	p.middleware = append(p.middleware, middleware...)
+}
+
+// wrap оборачивает этап всеми middleware pipeline
+func (p *Pipeline) wrap(name string, stage Stage) (wrapped Stage) {
+
+	wrapped = stage
+	for i := len(p.middleware) - 1; i >= 0; i-- {
+		wrapped = p.middleware[i](name, wrapped)
+	}
+	return
+}
+
+// runStage выполняет один этап с хуками, middleware и телеметрией
+func (p *Pipeline) runStage(ctx context.Context, index int, stage Stage, data Data) (result Data, err error) {
+
+	event := StageEvent{Stage: StageName(stage), Index: index, Input: data}
+	for _, hooks := range p.hooks {
+		if hooks.BeforeStage == nil {
+			continue
+		}
+		if err = hooks.BeforeStage(ctx, event); err != nil {
+			result = data
+			return
+		}
+	}
+
+	event.Started = time.Now()
+	event.Output, event.Err = p.telemetry.process(ctx, named(event.Stage, p.wrap(event.Stage, stage)), data)
+	event.Duration = time.Since(event.Started)
+	result, err = event.Output, event.Err
+
+	if err != nil {
+		for _, hooks := range p.hooks {
+			if hooks.OnError == nil {
+				continue
+			}
+			err = hooks.OnError(ctx, event)
+			if event.Err = err; err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return
+		}
+		// Упавший этап обычно возвращает пустые данные: продолжаем с его входом, чтобы не потерять пакет
+		if event.Output.Package == nil {
+			event.Output = event.Input
+			result = event.Input
+		}
+	}
+	for _, hooks := range p.hooks {
+		if hooks.AfterStage == nil {
+			continue
+		}
+		if err = hooks.AfterStage(ctx, event); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// named сохраняет имя этапа после обертывания middleware
+func named(name string, stage Stage) (named Stage) {
+
+	if StageName(stage) == name {
+		named = stage
+		return
+	}
+	named = Named(name, stage)
+	return
+}
@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// countingStage добавляет интерфейс с именем этапа
+func countingStage(name string) (stage NamedStage) {
+
+	stage = Named(name, StageFunc(func(ctx context.Context, data Data) (result Data, err error) {
+
+		data.Interfaces = append(data.Interfaces, models.Interface{Name: name})
+		result = data
+		return
+	}))
+	return
+}
+
+// TestHooksLifecycle проверяет порядок вызова хуков, middleware и данные событий
+func TestHooksLifecycle(t *testing.T) {
+
+	var calls []string
+	p := NewPipeline(countingStage("first"), countingStage("second"))
+	p.AddHooks(Hooks{
+		BeforeStage: func(ctx context.Context, event StageEvent) (err error) {
+			calls = append(calls, "before "+event.Stage)
+			return
+		},
+		AfterStage: func(ctx context.Context, event StageEvent) (err error) {
+			calls = append(calls, "after "+event.Stage)
+			if len(event.Output.Interfaces) != event.Index+1 || len(event.Input.Interfaces) != event.Index {
+				t.Errorf("Unexpected event data for stage %s: %+v", event.Stage, event)
+			}
+			if event.Started.IsZero() || event.Duration < 0 {
+				t.Errorf("Expected timing for stage %s, got %+v", event.Stage, event)
+			}
+			return
+		},
+	})
+	p.Use(func(name string, next Stage) (stage Stage) {
+		stage = StageFunc(func(ctx context.Context, data Data) (result Data, err error) {
+
+			calls = append(calls, "middleware "+name)
+			result, err = next.Process(ctx, data)
+			return
+		})
+		return
+	})
+
+	result, err := p.Execute(context.Background(), Data{})
+	if err != nil {
+		t.Fatalf("Pipeline execution failed: %v", err)
+	}
+	if len(result.Interfaces) != 2 {
+		t.Errorf("Expected 2 interfaces, got %d", len(result.Interfaces))
+	}
+	expected := []string{"before first", "middleware first", "after first", "before second", "middleware second", "after second"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+}
+
+// TestHooksShortCircuit проверяет досрочное завершение и обработку ошибок этапов
+func TestHooksShortCircuit(t *testing.T) {
+
+	p := NewPipeline(countingStage("first"), countingStage("second"))
+	p.AddHooks(Hooks{
+		AfterStage: func(ctx context.Context, event StageEvent) (err error) {
+			if event.Stage == "first" {
+				err = ErrSkipRemaining
+			}
+			return
+		},
+	})
+	result, err := p.Execute(context.Background(), Data{})
+	if err != nil || len(result.Interfaces) != 1 {
+		t.Errorf("Expected pipeline to stop after first stage, got %d interfaces and %v", len(result.Interfaces), err)
+	}
+
+	stageErr := errors.New("stage failed")
+	failing := Named("failing", StageFunc(func(ctx context.Context, data Data) (result Data, err error) {
+
+		result, err = data, stageErr
+		return
+	}))
+	var handled error
+	p = NewPipeline(failing, countingStage("second"))
+	p.AddHooks(Hooks{
+		OnError: func(ctx context.Context, event StageEvent) (err error) {
+			handled = event.Err
+			return
+		},
+	})
+	if result, err = p.Execute(context.Background(), Data{}); err != nil || len(result.Interfaces) != 1 {
+		t.Errorf("Expected handled error to continue pipeline, got %d interfaces and %v", len(result.Interfaces), err)
+	}
+	if !errors.Is(handled, stageErr) {
+		t.Errorf("Expected OnError to receive stage error, got %v", handled)
+	}
+
+	// Этап, вернувший при ошибке пустые данные, не теряет пакет для следующих этапов
+	empty := Named("empty", StageFunc(func(ctx context.Context, data Data) (result Data, err error) {
+
+		err = stageErr
+		return
+	}))
+	p = NewPipeline(countingStage("first"), empty, countingStage("second"))
+	p.AddHooks(Hooks{OnError: func(ctx context.Context, event StageEvent) (err error) { return }})
+	pkg := &models.Package{PackagePath: "service"}
+	if result, err = p.Execute(context.Background(), Data{Package: pkg}); err != nil || result.Package != pkg || len(result.Interfaces) != 2 {
+		t.Errorf("Expected input of the failed stage to be kept, got %+v and %v", result, err)
+	}
+}
+
+// TestHooksCancellation проверяет, что отмена ctx останавливает pipeline между этапами и внутри этапов
+func TestHooksCancellation(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelling := Named("cancelling", StageFunc(func(ctx context.Context, data Data) (result Data, err error) {
+
+		cancel()
+		result = data
+		return
+	}))
+	result, err := NewPipeline(cancelling, countingStage("second")).Execute(ctx, Data{})
+	if !errors.Is(err, context.Canceled) || len(result.Interfaces) != 0 {
+		t.Errorf("Expected cancellation before second stage, got %d interfaces and %v", len(result.Interfaces), err)
+	}
+
+	_, err = NewStageAST(models.NewAnnotationParser("@asti")).Process(ctx, Data{
+		Package: &models.Package{PackagePath: "service"},
+		FS: NewOverlayFileSystem(nil, map[string][]byte{
+			"service/service.go": []byte(diagnosticServiceFile),
+		}),
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected StageAST to honor cancellation, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// StageInheritance вычисляет действующие аннотации с учетом наследования: пакет → интерфейс → метод,
+// тип → поле. Собственные аннотации элементов не меняются.
+type StageInheritance struct {
+	rules models.InheritanceRules
+}
+
+func NewStageInheritance(rules models.InheritanceRules) (stage *StageInheritance) {
+
+	stage = &StageInheritance{rules: rules}
+	return
+}
+
+func (s *StageInheritance) Name() (name string) {
+
+	name = StageNameInheritance
+	return
+}
+
+// Process заполняет Effective и Provenance интерфейсов, методов, типов и полей
+func (s *StageInheritance) Process(ctx context.Context, data Data) (result Data, err error) {
+
+	var packageAnnotations models.Annotations
+	if data.Package != nil {
+		packageAnnotations = s.rules.Resolve(nil, data.Package.Annotations, models.TargetPackage)
+	}
+	for i := range data.Interfaces {
+		iface := &data.Interfaces[i]
+		iface.Effective = s.rules.Resolve(packageAnnotations, iface.Annotations, models.TargetInterface)
+		iface.Provenance = iface.Effective.Provenance()
+		for j := range iface.Methods {
+			method := &iface.Methods[j]
+			method.Effective = s.rules.Resolve(iface.Effective, method.Annotations, models.TargetMethod)
+			method.Provenance = method.Effective.Provenance()
+		}
+	}
+	for key, typeInfo := range data.Types {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		typeInfo.Effective = s.rules.Resolve(nil, typeInfo.Annotations, models.TargetType)
+		typeInfo.Provenance = typeInfo.Effective.Provenance()
+		if len(typeInfo.Fields) > 0 {
+			fields := make([]models.FieldInfo, len(typeInfo.Fields))
+			for i, field := range typeInfo.Fields {
+				field.Effective = s.rules.Resolve(typeInfo.Effective, field.Annotations, models.TargetField)
+				field.Provenance = field.Effective.Provenance()
+				fields[i] = field
+			}
+			typeInfo.Fields = fields
+		}
+		data.Types[key] = typeInfo
+	}
+	result = data
+	return
+}
@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"go/build"
+	"go/token"
+)
+
+// Key типизированный ключ метаданных pipeline. Ключи сравниваются по идентичности:
+// два ключа, созданные NewKey с одинаковым именем, различны.
+type Key[T any] struct {
+	id *keyID
+}
+
+type keyID struct {
+	name string
+}
+
+// NewKey создает ключ метаданных; name используется только для отладки
+func NewKey[T any](name string) (key Key[T]) {
+
+	key = Key[T]{id: &keyID{name: name}}
+	return
+}
+
+// Name возвращает имя ключа
+func (k Key[T]) Name() (name string) {
+
+	if k.id != nil {
+		name = k.id.name
+	}
+	return
+}
+
+// Ключи метаданных, которые заполняют встроенные этапы
+var (
+	// KeyModuleRoot абсолютный путь корня модуля (директория go.mod)
+	KeyModuleRoot = NewKey[string]("module-root")
+	// KeyAbsolutePackagePath абсолютный путь директории пакета
+	KeyAbsolutePackagePath = NewKey[string]("absolute-package-path")
+	// KeyGoVersion версия Go из директивы go в go.mod
+	KeyGoVersion = NewKey[string]("go-version")
+	// KeyBuildContext контекст сборки, по которому отобраны файлы пакета
+	KeyBuildContext = NewKey[*build.Context]("build-context")
+	// KeyFileSet набор позиций разобранных файлов пакета
+	KeyFileSet = NewKey[*token.FileSet]("file-set")
+)
+
+// SetValue сохраняет значение по ключу. Хранилище копируется при записи, поэтому
+// копии Data, сохраненные ранее (например, хуками), не меняются.
+func SetValue[T any](data *Data, key Key[T], value T) {
+
+	values := make(map[*keyID]any, len(data.values)+1)
+	for id, existing := range data.values {
+		values[id] = existing
+	}
+	values[key.id] = value
+	data.values = values
+}
+
+// Value возвращает значение по ключу и признак его наличия
+func Value[T any](data Data, key Key[T]) (value T, found bool) {
+
+	var stored any
+	if stored, found = data.values[key.id]; found {
+		value, found = stored.(T)
+	}
+	return
+}
+
+// DeleteValue удаляет значение по ключу
+func DeleteValue[T any](data *Data, key Key[T]) {
+
+	if _, found := data.values[key.id]; !found {
+		return
+	}
+	values := make(map[*keyID]any, len(data.values))
+	for id, existing := range data.values {
+		if id != key.id {
+			values[id] = existing
+		}
+	}
+	data.values = values
+}
+
+// absolutePackagePath возвращает абсолютный путь пакета, сохраненный StageModule,
+// либо путь пакета, если StageModule не выполнялся
+func absolutePackagePath(data Data) (packagePath string) {
+
+	if packagePath, _ = Value(data, KeyAbsolutePackagePath); packagePath == "" && data.Package != nil {
+		packagePath = data.Package.PackagePath
+	}
+	return
+}
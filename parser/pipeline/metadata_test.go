@@ -0,0 +1,41 @@
+package pipeline
+
+import (
+	"testing"
+)
+
+// TestMetadata проверяет типизированные ключи и копирование хранилища при записи
+func TestMetadata(t *testing.T) {
+
+	counter := NewKey[int]("counter")
+	other := NewKey[int]("counter")
+
+	var data Data
+	if _, found := Value(data, counter); found {
+		t.Fatal("Expected empty metadata")
+	}
+	SetValue(&data, counter, 1)
+	snapshot := data
+	SetValue(&data, counter, 2)
+	SetValue(&data, other, 3)
+
+	if value, _ := Value(data, counter); value != 2 {
+		t.Errorf("Expected counter 2, got %d", value)
+	}
+	if value, _ := Value(data, other); value != 3 {
+		t.Errorf("Expected keys with the same name to be distinct, got %d", value)
+	}
+	if value, _ := Value(snapshot, counter); value != 1 {
+		t.Errorf("Expected snapshot to keep counter 1, got %d", value)
+	}
+	DeleteValue(&data, counter)
+	if _, found := Value(data, counter); found {
+		t.Error("Expected counter to be deleted")
+	}
+	if _, found := Value(snapshot, counter); !found {
+		t.Error("Expected delete not to affect snapshot")
+	}
+	if counter.Name() != "counter" {
+		t.Errorf("Unexpected key name %q", counter.Name())
+	}
+}
@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/seniorGolang/asti/parser/models"
@@ -15,6 +16,12 @@ func NewStageModule() (stage *StageModule) {
 	return
 }
 
+func (s *StageModule) Name() (name string) {
+
+	name = StageNameModule
+	return
+}
+
 // Process извлекает информацию о модуле из go.mod файла
 func (s *StageModule) Process(ctx context.Context, data Data) (result Data, err error) {
 	if data.Package == nil {
@@ -23,18 +30,24 @@ func (s *StageModule) Process(ctx context.Context, data Data) (result Data, err
 	}
 
 	// Ищем go.mod файл, начиная с директории пакета и поднимаясь вверх
-	moduleRootPath, moduleName, findErr := s.findModuleInfo(data.Package.PackagePath)
+	var moduleRootPath, moduleName, goVersion string
+	var findErr error
+	if data.FS == nil {
+		moduleRootPath, moduleName, goVersion, findErr = s.findModuleInfo(data.Package.PackagePath)
+	} else {
+		moduleRootPath, moduleName, goVersion, findErr = s.findModuleInfoFS(data.FS, data.Package.PackagePath)
+	}
+	// Сохраняем абсолютный путь для использования другими этапами
+	SetValue(&data, KeyAbsolutePackagePath, data.Package.PackagePath)
+	SetValue(&data, KeyBuildContext, buildContextOf(data))
 	if findErr != nil {
-		// Не считаем это критической ошибкой, просто логируем и продолжаем
-		fmt.Printf("Warning: failed to find module info: %v\n", findErr)
-		// Для пустых директорий или директорий без модуля продолжаем работу
+		// Не считаем это критической ошибкой, сообщаем предупреждением и продолжаем
 		// с абсолютным путем
-		if data.Annotations == nil {
-			data.Annotations = make(map[string]models.Annotations)
-		}
-		data.Annotations["_absolutePackagePath"] = models.Annotations{
-			"path": data.Package.PackagePath,
-		}
+		data.Report(models.Diagnostic{
+			Severity: models.SeverityWarning,
+			Code:     models.CodeModuleNotFound,
+			Message:  fmt.Sprintf("failed to find module info: %v", findErr),
+		})
 	} else {
 		// Преобразуем абсолютные пути в относительные от корня модуля
 		packagePath, err := filepath.Rel(moduleRootPath, data.Package.PackagePath)
@@ -43,18 +56,12 @@ func (s *StageModule) Process(ctx context.Context, data Data) (result Data, err
 			packagePath = data.Package.PackagePath
 		}
 
-		// Сохраняем абсолютный путь для внутреннего использования
-		absolutePackagePath := data.Package.PackagePath
-
 		data.Package.ModuleName = moduleName
 		data.Package.PackagePath = packagePath
 
-		// Добавляем абсолютный путь в данные для использования другими этапами
-		if data.Annotations == nil {
-			data.Annotations = make(map[string]models.Annotations)
-		}
-		data.Annotations["_absolutePackagePath"] = models.Annotations{
-			"path": absolutePackagePath,
+		SetValue(&data, KeyModuleRoot, moduleRootPath)
+		if goVersion != "" {
+			SetValue(&data, KeyGoVersion, goVersion)
 		}
 	}
 
@@ -63,17 +70,40 @@ func (s *StageModule) Process(ctx context.Context, data Data) (result Data, err
 }
 
 // findModuleInfo ищет go.mod файл и извлекает информацию о модуле
-func (s *StageModule) findModuleInfo(packagePath string) (moduleRootPath, moduleName string, err error) {
+func (s *StageModule) findModuleInfo(packagePath string) (moduleRootPath, moduleName, goVersion string, err error) {
 	// Используем общую функцию для поиска корня модуля
 	moduleRootPath, err = FindModuleRoot(packagePath)
 	if err != nil {
 		return
 	}
-	
-	// Парсим go.mod файл для получения имени модуля
-	goModPath := filepath.Join(moduleRootPath, "go.mod")
-	moduleName, err = ParseGoMod(goModPath)
+
+	// Парсим go.mod файл для получения имени модуля и версии Go
+	var content []byte
+	if content, err = os.ReadFile(filepath.Join(moduleRootPath, "go.mod")); err != nil {
+		err = fmt.Errorf("failed to read go.mod file: %w", err)
+		return
+	}
+	if moduleName, err = parseGoModContent(content); err != nil {
+		return
+	}
+	goVersion = parseGoVersion(content)
 	return
 }
 
+// findModuleInfoFS ищет go.mod и извлекает имя модуля в виртуальной файловой системе
+func (s *StageModule) findModuleInfoFS(fileSystem FileSystem, packagePath string) (moduleRootPath, moduleName, goVersion string, err error) {
 
+	if moduleRootPath, err = findModuleRootFS(fileSystem, packagePath); err != nil {
+		return
+	}
+	var content []byte
+	if content, err = fileSystem.ReadFile(filepath.Join(moduleRootPath, "go.mod")); err != nil {
+		err = fmt.Errorf("failed to read go.mod file: %w", err)
+		return
+	}
+	if moduleName, err = parseGoModContent(content); err != nil {
+		return
+	}
+	goVersion = parseGoVersion(content)
+	return
+}
@@ -77,13 +77,27 @@ type User struct {
 	if result.Package.PackagePath != expectedPackagePath {
 		t.Errorf("Expected package path '%s', got '%s'", expectedPackagePath, result.Package.PackagePath)
 	}
+
+	// Проверяем метаданные для следующих этапов
+	if moduleRoot, _ := Value(result, KeyModuleRoot); moduleRoot != tempDir {
+		t.Errorf("Expected module root '%s', got '%s'", tempDir, moduleRoot)
+	}
+	if absolutePath, _ := Value(result, KeyAbsolutePackagePath); absolutePath != packageDir {
+		t.Errorf("Expected absolute package path '%s', got '%s'", packageDir, absolutePath)
+	}
+	if goVersion, _ := Value(result, KeyGoVersion); goVersion != "1.24" {
+		t.Errorf("Expected go version '1.24', got '%s'", goVersion)
+	}
+	if result.Annotations != nil {
+		t.Errorf("Expected annotations to stay empty, got %v", result.Annotations)
+	}
 }
 
 func TestStageModule_findModuleInfo(t *testing.T) {
 	stage := NewStageModule()
 
 	// Тест с несуществующей директорией
-	_, _, err := stage.findModuleInfo("/non/existent/path")
+	_, _, _, err := stage.findModuleInfo("/non/existent/path")
 	if err == nil {
 		t.Error("Expected error for non-existent path, got nil")
 	}
@@ -56,6 +56,25 @@ func FindModuleRoot(packagePath string) (moduleRoot string, err error) {
 	return
 }
 
+// findModuleRootFS ищет директорию с go.mod, поднимаясь вверх по файловой системе pipeline
+func findModuleRootFS(fileSystem FileSystem, packagePath string) (moduleRoot string, err error) {
+
+	currentPath := filepath.Clean(packagePath)
+	for {
+		if _, statErr := fileSystem.Stat(filepath.Join(currentPath, "go.mod")); statErr == nil {
+			moduleRoot = currentPath
+			return
+		}
+		parent := filepath.Dir(currentPath)
+		if parent == currentPath {
+			break
+		}
+		currentPath = parent
+	}
+	err = fmt.Errorf("module root not found")
+	return
+}
+
 // ParseGoMod парсит go.mod файл и извлекает имя модуля
 func ParseGoMod(goModPath string) (moduleName string, err error) {
 	content, err := os.ReadFile(goModPath)
@@ -63,6 +82,12 @@ func ParseGoMod(goModPath string) (moduleName string, err error) {
 		err = fmt.Errorf("failed to read go.mod file: %w", err)
 		return
 	}
+	moduleName, err = parseGoModContent(content)
+	return
+}
+
+// parseGoModContent извлекает имя модуля из содержимого go.mod
+func parseGoModContent(content []byte) (moduleName string, err error) {
 
 	lines := strings.Split(string(content), "\n")
 	for _, line := range lines {
@@ -80,4 +105,17 @@ func ParseGoMod(goModPath string) (moduleName string, err error) {
 
 	err = fmt.Errorf("module declaration not found in go.mod file")
 	return
-} 
\ No newline at end of file
+}
+
+// parseGoVersion извлекает версию Go из директивы go в содержимом go.mod
+func parseGoVersion(content []byte) (goVersion string) {
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "go" {
+			goVersion = fields[1]
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,206 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// Префиксы ключей аннотаций метода, адресованных параметрам и результатам: param.userID=path
+const (
+	parameterPrefix = "param"
+	resultPrefix    = "result"
+)
+
+// fieldComments распределяет комментарии внутри скобок списка fieldList по его полям: комментарий
+// в строке поля после него относится к этому полю, остальные — к следующему полю списка
+func fieldComments(fieldList *ast.FieldList, comments []*ast.CommentGroup, fset *token.FileSet) (groups map[*ast.Field][]*ast.CommentGroup) {
+
+	if fieldList == nil || !fieldList.Opening.IsValid() || !fieldList.Closing.IsValid() || len(fieldList.List) == 0 {
+		return
+	}
+	for _, group := range comments {
+		if group.Pos() <= fieldList.Opening || group.End() > fieldList.Closing {
+			continue
+		}
+		var previous, target *ast.Field
+		for _, field := range fieldList.List {
+			if field.End() <= group.Pos() {
+				previous = field
+				continue
+			}
+			if field.Pos() >= group.End() {
+				target = field
+			}
+			break
+		}
+		if previous != nil && fset.Position(previous.End()).Line == fset.Position(group.Pos()).Line {
+			target = previous
+		}
+		if target == nil {
+			continue
+		}
+		if groups == nil {
+			groups = make(map[*ast.Field][]*ast.CommentGroup)
+		}
+		groups[target] = append(groups[target], group)
+	}
+	return
+}
+
+// targetAnnotations возвращает аннотации переменной name из ключей prefix.name.ключ.
+// Значение-объект prefix.name={ключ: значение} раскрывается в поля, другое значение prefix.name
+// сохраняется с ключом prefix.
+func targetAnnotations(annotations models.Annotations, prefix string, name string) (targeted models.Annotations) {
+
+	if name == "" {
+		return
+	}
+	scope := prefix + "." + name
+	for _, annotation := range annotations {
+		switch {
+		case annotation.Key == scope && annotation.Value.Kind == models.KindObject:
+			targeted = append(targeted, annotation.Value.Fields...)
+		case annotation.Key == scope:
+			targeted = append(targeted, models.Annotation{Key: prefix, Value: annotation.Value})
+		case strings.HasPrefix(annotation.Key, scope+"."):
+			targeted = append(targeted, models.Annotation{Key: strings.TrimPrefix(annotation.Key, scope+"."), Value: annotation.Value})
+		}
+	}
+	return
+}
+
+// variableName возвращает имя переменной из ключа prefix.name[.ключ] или false, если ключ не адресован переменным
+func variableName(key string, prefix string) (name string, ok bool) {
+
+	if !strings.HasPrefix(key, prefix+".") {
+		return
+	}
+	name, _, _ = strings.Cut(strings.TrimPrefix(key, prefix+"."), ".")
+	ok = name != ""
+	return
+}
+
+// attachVariableAnnotations переносит на параметры и результаты метода ключи param.имя и result.имя
+// его аннотаций и пространств имен и убирает их из аннотаций метода. Ключ с неизвестным именем
+// переменной сохраняется в diagnostics.
+func attachVariableAnnotations(method *models.Method, diagnostics *[]models.Diagnostic) {
+
+	attach := func(variables []models.Variable, prefix string) {
+		known := make(map[string]bool, len(variables))
+		for i := range variables {
+			known[variables[i].Name] = variables[i].Name != ""
+			// Ключи метода идут перед комментариями переменной: значение из комментария рядом с ней приоритетнее
+			annotations := targetAnnotations(method.Annotations, prefix, variables[i].Name)
+			if annotations != nil {
+				variables[i].Annotations = append(annotations, variables[i].Annotations...)
+			}
+			for namespace, namespaceAnnotations := range method.Namespaces {
+				if annotations = targetAnnotations(namespaceAnnotations, prefix, variables[i].Name); annotations == nil {
+					continue
+				}
+				if variables[i].Namespaces == nil {
+					variables[i].Namespaces = models.Namespaces{}
+				}
+				variables[i].Namespaces[namespace] = append(annotations, variables[i].Namespaces[namespace]...)
+			}
+		}
+		report := func(annotations models.Annotations) {
+			for _, annotation := range annotations {
+				if name, ok := variableName(annotation.Key, prefix); ok && !known[name] {
+					position := annotation.Value.Position
+					if position.Line == 0 {
+						position = method.Position
+					}
+					*diagnostics = append(*diagnostics, models.Diagnostic{
+						Severity: models.SeverityWarning,
+						Code:     models.CodeAnnotationTarget,
+						Message:  fmt.Sprintf("method %s has no %s named %s for annotation %s", method.Name, variableKind(prefix), name, annotation.Key),
+						Position: position,
+					})
+				}
+			}
+		}
+		report(method.Annotations)
+		namespaces := make([]string, 0, len(method.Namespaces))
+		for namespace := range method.Namespaces {
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+		for _, namespace := range namespaces {
+			report(method.Namespaces[namespace])
+		}
+	}
+	attach(method.Parameters, parameterPrefix)
+	attach(method.Results, resultPrefix)
+
+	// Перенесенные ключи не остаются у метода, чтобы не попадать в Effective и проверку схемой
+	method.Annotations = withoutVariableKeys(method.Annotations)
+	for namespace, annotations := range method.Namespaces {
+		if annotations = withoutVariableKeys(annotations); annotations == nil {
+			delete(method.Namespaces, namespace)
+			continue
+		}
+		method.Namespaces[namespace] = annotations
+	}
+	if len(method.Namespaces) == 0 {
+		method.Namespaces = nil
+	}
+}
+
+// withoutVariableKeys возвращает аннотации без ключей param.имя и result.имя или nil, если других ключей нет
+func withoutVariableKeys(annotations models.Annotations) (filtered models.Annotations) {
+
+	for _, annotation := range annotations {
+		if _, ok := variableName(annotation.Key, parameterPrefix); ok {
+			continue
+		}
+		if _, ok := variableName(annotation.Key, resultPrefix); ok {
+			continue
+		}
+		filtered = append(filtered, annotation)
+	}
+	return
+}
+
+// variableKind возвращает название вида переменных для текста диагностики
+func variableKind(prefix string) (kind string) {
+
+	kind = "parameter"
+	if prefix == resultPrefix {
+		kind = "result"
+	}
+	return
+}
+
+// cloneNamespaces возвращает копию аннотаций пространств имен
+func cloneNamespaces(namespaces models.Namespaces) (cloned models.Namespaces) {
+
+	for namespace, annotations := range namespaces {
+		cloned.Merge(namespace, annotations)
+	}
+	return
+}
+
+// readVariableComments разбирает аннотации комментариев groups, относящихся к переменной
+func (r annotationReader) readVariableComments(ctx context.Context, groups []*ast.CommentGroup, fset *token.FileSet, packagePath string, diagnostics *[]models.Diagnostic) (annotations models.Annotations, namespaces models.Namespaces) {
+
+	for _, group := range groups {
+		groupAnnotations, groupNamespaces := r.read(ctx, group, fset, packagePath, diagnostics)
+		if groupAnnotations != nil {
+			if annotations == nil {
+				annotations = models.Annotations{}
+			}
+			annotations.Merge(groupAnnotations)
+		}
+		for namespace, namespaceAnnotations := range groupNamespaces {
+			namespaces.Merge(namespace, namespaceAnnotations)
+		}
+	}
+	return
+}
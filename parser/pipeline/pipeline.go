@@ -2,6 +2,11 @@ package pipeline
 
 import (
 	"context"
+	"errors"
+	"go/build"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/seniorGolang/asti/parser/models"
 )
@@ -23,31 +28,73 @@ type Data struct {
 	Interfaces  []models.Interface
 	Types       map[string]models.TypeInfo
 	Annotations map[string]models.Annotations
-	Errors      []error
+	// Diagnostics проблемы, найденные этапами; попадают в Package.Diagnostics
+	Diagnostics []models.Diagnostic
+	// FS источник файлов пакета, nil означает файловую систему ОС
+	FS FileSystem
+	// Build контекст сборки для отбора файлов, nil означает build.Default
+	Build *build.Context
+	// Partial разрешает частичный результат: файлы с синтаксическими ошибками разбираются
+	// с восстановлением (parser.AllErrors), ошибки попадают в Diagnostics
+	Partial bool
+	// Files разобранные файлы пакета, заполняются первым обратившимся к ним этапом
+	Files *PackageFiles
+	// values типизированные метаданные этапов, см. SetValue и Value
+	values map[*keyID]any
+}
+
+// Report добавляет диагностику к данным pipeline. Повторная диагностика с тем же кодом,
+// сообщением и позицией (например, аннотация, прочитанная двумя этапами) не добавляется.
+func (d *Data) Report(diagnostic models.Diagnostic) {
+
+	for _, existing := range d.Diagnostics {
+		if existing.Code == diagnostic.Code && existing.Message == diagnostic.Message && existing.Position == diagnostic.Position {
+			return
+		}
+	}
+	d.Diagnostics = append(d.Diagnostics, diagnostic)
 }
 
 type Pipeline struct {
-	stages []Stage
+	stages     []Stage
+	hooks      []Hooks
+	middleware []Middleware
+	telemetry  *telemetry
 }
 
 func NewPipeline(stages ...Stage) (pipeline *Pipeline) {
 
-	pipeline = &Pipeline{stages: stages}
+	pipeline = &Pipeline{stages: stages, telemetry: newTelemetry(nil, nil)}
 	return
 }
 
+// Instrument задает провайдеры OpenTelemetry для спанов и метрик этапов;
+// nil означает глобальный провайдер otel
+func (p *Pipeline) Instrument(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) {
+
+	p.telemetry = newTelemetry(tracerProvider, meterProvider)
+}
+
 func (p *Pipeline) AddStage(stage Stage) {
 
 	p.stages = append(p.stages, stage)
 }
 
-// Execute выполняет все этапы pipeline
+// Execute выполняет все этапы pipeline. Отмена ctx проверяется перед каждым этапом
+// и внутри этапов, обрабатывающих файлы пакета.
 func (p *Pipeline) Execute(ctx context.Context, initialData Data) (result Data, err error) {
 
 	data := initialData
-	for _, stage := range p.stages {
-		if data, err = stage.Process(ctx, data); err != nil {
+	for index, stage := range p.stages {
+		if err = ctx.Err(); err != nil {
+			result = data
+			return
+		}
+		if data, err = p.runStage(ctx, index, stage, data); err != nil {
 			result = data
+			if errors.Is(err, ErrSkipRemaining) {
+				err = nil
+			}
 			return
 		}
 	}
@@ -0,0 +1,79 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// writeLargePackage создает временный модуль с пакетом из fileCount файлов
+func writeLargePackage(tb testing.TB, fileCount int) (packageDir string) {
+
+	tempDir := tb.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module github.com/test/large\n\ngo 1.24\n"), 0600); err != nil {
+		tb.Fatalf("Failed to write go.mod file: %v", err)
+	}
+	packageDir = filepath.Join(tempDir, "service")
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		tb.Fatalf("Failed to create package directory: %v", err)
+	}
+	for i := 0; i < fileCount; i++ {
+		content := fmt.Sprintf(`package service
+
+import (
+	"context"
+	"time"
+)
+
+// @asti name=Service%[1]d
+type Service%[1]d interface {
+	// @asti method=Get
+	Get(ctx context.Context, id string) (item *Item%[1]d, err error)
+}
+
+// @asti type=Item%[1]d
+type Item%[1]d struct {
+	// @asti field=ID
+	ID        string `+"`json:\"id\"`"+`
+	CreatedAt time.Time
+	Next      *Item%[2]d
+}
+`, i, (i+1)%fileCount)
+		if err := os.WriteFile(filepath.Join(packageDir, fmt.Sprintf("service_%d.go", i)), []byte(content), 0600); err != nil {
+			tb.Fatalf("Failed to write Go file: %v", err)
+		}
+	}
+	return
+}
+
+// BenchmarkPipelineLargePackage измеряет выполнение этапов pipeline на пакете из 300 файлов
+func BenchmarkPipelineLargePackage(b *testing.B) {
+
+	packageDir := writeLargePackage(b, 300)
+	annotationParser := models.NewAnnotationParser("@asti")
+	pipeline := NewPipeline(
+		NewStageModule(),
+		NewStageAST(annotationParser),
+		NewStageFilter(),
+		NewStageTypeCollection(annotationParser),
+		NewStageSerialization(),
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := pipeline.Execute(context.Background(), Data{
+			Package: &models.Package{PackagePath: packageDir},
+		})
+		if err != nil {
+			b.Fatalf("Pipeline execution failed: %v", err)
+		}
+		if len(result.Interfaces) != 300 {
+			b.Fatalf("Expected 300 interfaces, got %d", len(result.Interfaces))
+		}
+	}
+}
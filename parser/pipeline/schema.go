@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+	"sort"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+// StageSchema проверяет разобранные аннотации по схеме и сообщает нарушения диагностиками
+type StageSchema struct {
+	schema *models.AnnotationSchema
+}
+
+func NewStageSchema(schema *models.AnnotationSchema) (stage *StageSchema) {
+
+	stage = &StageSchema{schema: schema}
+	return
+}
+
+func (s *StageSchema) Name() (name string) {
+
+	name = StageNameSchema
+	return
+}
+
+// Process проверяет аннотации пакета, интерфейсов, методов, типов и полей. Пакет, интерфейсы и типы
+// проверяются, если у них есть аннотации; методы аннотированных интерфейсов и поля аннотированных типов —
+// всегда, чтобы обязательный ключ сообщался и для элемента без аннотаций.
+func (s *StageSchema) Process(ctx context.Context, data Data) (result Data, err error) {
+
+	report := func(diagnostics []models.Diagnostic) {
+		for _, diagnostic := range diagnostics {
+			data.Report(diagnostic)
+		}
+	}
+	if data.Package != nil && len(data.Package.Annotations) > 0 {
+		report(s.schema.Validate(models.TargetPackage, data.Package.PackagePath, data.Package.Annotations, data.Package.Annotations[0].Value.Position))
+	}
+	interfaceIDs := make(map[string]bool, len(data.Interfaces))
+	for _, iface := range data.Interfaces {
+		interfaceIDs[iface.ID] = true
+		if len(iface.Annotations) == 0 {
+			continue
+		}
+		report(s.schema.Validate(models.TargetInterface, iface.Name, iface.Annotations, iface.Position))
+		for _, method := range iface.Methods {
+			report(s.schema.Validate(models.TargetMethod, iface.Name+"."+method.Name, method.Annotations, method.Position))
+		}
+	}
+	typeKeys := make([]string, 0, len(data.Types))
+	for key := range data.Types {
+		// Интерфейсы уже проверены по схеме interface
+		if !interfaceIDs[key] {
+			typeKeys = append(typeKeys, key)
+		}
+	}
+	sort.Strings(typeKeys)
+	for _, key := range typeKeys {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		typeInfo := data.Types[key]
+		if len(typeInfo.Annotations) == 0 {
+			continue
+		}
+		report(s.schema.Validate(models.TargetType, key, typeInfo.Annotations, typeInfo.Position))
+		for _, field := range typeInfo.Fields {
+			report(s.schema.Validate(models.TargetField, key+"."+field.Name, field.Annotations, field.Position))
+		}
+	}
+	result = data
+	return
+}
@@ -16,6 +16,12 @@ func NewStageSerialization() (stage *StageSerialization) {
 	return
 }
 
+func (s *StageSerialization) Name() (name string) {
+
+	name = StageNameSerialization
+	return
+}
+
 // Process выполняет сериализацию результата
 func (s *StageSerialization) Process(ctx context.Context, data Data) (result Data, err error) {
 
@@ -24,6 +30,7 @@ func (s *StageSerialization) Process(ctx context.Context, data Data) (result Dat
 	}
 	data.Package.Interfaces = data.Interfaces
 	data.Package.Types = data.Types
+	data.Package.Diagnostics = data.Diagnostics
 	if err = s.validatePackage(data.Package); err != nil {
 		err = fmt.Errorf("package validation failed: %w", err)
 		return
@@ -81,5 +88,20 @@ func (s *StageSerialization) FromJSON(jsonData []byte) (pkg *models.Package, err
 	if err = json.Unmarshal(jsonData, pkg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
 	}
+	// Уровни действующих аннотаций хранятся в JSON отдельно, в provenance
+	for i := range pkg.Interfaces {
+		iface := &pkg.Interfaces[i]
+		iface.Effective = iface.Effective.WithProvenance(iface.Provenance)
+		for j := range iface.Methods {
+			iface.Methods[j].Effective = iface.Methods[j].Effective.WithProvenance(iface.Methods[j].Provenance)
+		}
+	}
+	for key, typeInfo := range pkg.Types {
+		typeInfo.Effective = typeInfo.Effective.WithProvenance(typeInfo.Provenance)
+		for i := range typeInfo.Fields {
+			typeInfo.Fields[i].Effective = typeInfo.Fields[i].Effective.WithProvenance(typeInfo.Fields[i].Provenance)
+		}
+		pkg.Types[key] = typeInfo
+	}
 	return pkg, nil
 }
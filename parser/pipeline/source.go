@@ -0,0 +1,317 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileSystem абстрагирует доступ этапов pipeline к исходным файлам пакета
+type FileSystem interface {
+	ReadFile(name string) (content []byte, err error)
+	ReadDir(name string) (entries []fs.DirEntry, err error)
+	Stat(name string) (info fs.FileInfo, err error)
+}
+
+// OSFileSystem читает файлы с диска
+type OSFileSystem struct{}
+
+func (OSFileSystem) ReadFile(name string) (content []byte, err error) {
+
+	content, err = os.ReadFile(name)
+	return
+}
+
+func (OSFileSystem) ReadDir(name string) (entries []fs.DirEntry, err error) {
+
+	entries, err = os.ReadDir(name)
+	return
+}
+
+func (OSFileSystem) Stat(name string) (info fs.FileInfo, err error) {
+
+	info, err = os.Stat(name)
+	return
+}
+
+// FSFileSystem читает файлы из io/fs.FS (например, embed.FS)
+type FSFileSystem struct {
+	fsys fs.FS
+}
+
+func NewFSFileSystem(fsys fs.FS) (fileSystem *FSFileSystem) {
+
+	fileSystem = &FSFileSystem{fsys: fsys}
+	return
+}
+
+func (f *FSFileSystem) name(name string) (fsName string) {
+
+	fsName = path.Clean(filepath.ToSlash(name))
+	return
+}
+
+func (f *FSFileSystem) ReadFile(name string) (content []byte, err error) {
+
+	content, err = fs.ReadFile(f.fsys, f.name(name))
+	return
+}
+
+func (f *FSFileSystem) ReadDir(name string) (entries []fs.DirEntry, err error) {
+
+	entries, err = fs.ReadDir(f.fsys, f.name(name))
+	return
+}
+
+func (f *FSFileSystem) Stat(name string) (info fs.FileInfo, err error) {
+
+	info, err = fs.Stat(f.fsys, f.name(name))
+	return
+}
+
+// OverlayFileSystem подменяет содержимое файлов базовой файловой системы данными из памяти.
+// Без базовой файловой системы работает как чисто виртуальная файловая система.
+type OverlayFileSystem struct {
+	base  FileSystem
+	files map[string][]byte
+}
+
+func NewOverlayFileSystem(base FileSystem, files map[string][]byte) (fileSystem *OverlayFileSystem) {
+
+	fileSystem = &OverlayFileSystem{
+		base:  base,
+		files: make(map[string][]byte, len(files)),
+	}
+	for name, content := range files {
+		fileSystem.files[filepath.Clean(name)] = content
+	}
+	return
+}
+
+func (o *OverlayFileSystem) ReadFile(name string) (content []byte, err error) {
+
+	if data, found := o.files[filepath.Clean(name)]; found {
+		content = append([]byte(nil), data...)
+		return
+	}
+	if o.base == nil {
+		err = &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+		return
+	}
+	content, err = o.base.ReadFile(name)
+	return
+}
+
+func (o *OverlayFileSystem) ReadDir(name string) (entries []fs.DirEntry, err error) {
+
+	name = filepath.Clean(name)
+	byName := make(map[string]fs.DirEntry)
+	if o.base != nil {
+		var baseEntries []fs.DirEntry
+		if baseEntries, err = o.base.ReadDir(name); err != nil && !(os.IsNotExist(err) && o.isDir(name)) {
+			return
+		}
+		err = nil
+		for _, entry := range baseEntries {
+			byName[entry.Name()] = entry
+		}
+	} else if !o.isDir(name) {
+		err = &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+		return
+	}
+	for fileName, content := range o.files {
+		switch {
+		case filepath.Dir(fileName) == name:
+			byName[filepath.Base(fileName)] = fs.FileInfoToDirEntry(memoryFileInfo{name: filepath.Base(fileName), size: int64(len(content))})
+		case strings.HasPrefix(fileName, o.dirPrefix(name)):
+			child := strings.SplitN(strings.TrimPrefix(fileName, o.dirPrefix(name)), string(filepath.Separator), 2)[0]
+			if _, exists := byName[child]; !exists {
+				byName[child] = fs.FileInfoToDirEntry(memoryFileInfo{name: child, dir: true})
+			}
+		}
+	}
+	for _, entry := range byName {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return
+}
+
+func (o *OverlayFileSystem) Stat(name string) (info fs.FileInfo, err error) {
+
+	name = filepath.Clean(name)
+	if content, found := o.files[name]; found {
+		info = memoryFileInfo{name: filepath.Base(name), size: int64(len(content))}
+		return
+	}
+	if o.base != nil {
+		if info, err = o.base.Stat(name); err == nil || !os.IsNotExist(err) {
+			return
+		}
+	}
+	if o.isDir(name) {
+		info = memoryFileInfo{name: filepath.Base(name), dir: true}
+		err = nil
+		return
+	}
+	err = &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	return
+}
+
+// dirPrefix возвращает префикс путей файлов, лежащих внутри директории
+func (o *OverlayFileSystem) dirPrefix(dir string) (prefix string) {
+
+	switch dir {
+	case ".":
+		prefix = ""
+	case string(filepath.Separator):
+		prefix = dir
+	default:
+		prefix = dir + string(filepath.Separator)
+	}
+	return
+}
+
+// isDir проверяет, есть ли в overlay файлы внутри указанной директории
+func (o *OverlayFileSystem) isDir(dir string) (isDir bool) {
+
+	for fileName := range o.files {
+		for parent := filepath.Dir(fileName); ; parent = filepath.Dir(parent) {
+			if parent == dir {
+				isDir = true
+				return
+			}
+			if parent == filepath.Dir(parent) {
+				break
+			}
+		}
+	}
+	return
+}
+
+type memoryFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memoryFileInfo) Name() string       { return i.name }
+func (i memoryFileInfo) Size() int64        { return i.size }
+func (i memoryFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memoryFileInfo) IsDir() bool        { return i.dir }
+func (i memoryFileInfo) Sys() any           { return nil }
+
+func (i memoryFileInfo) Mode() (mode fs.FileMode) {
+
+	mode = 0444
+	if i.dir {
+		mode = fs.ModeDir | 0555
+	}
+	return
+}
+
+// fileSystemOf возвращает файловую систему из данных pipeline, по умолчанию — файловую систему ОС
+func fileSystemOf(data Data) (fileSystem FileSystem) {
+
+	fileSystem = data.FS
+	if fileSystem == nil {
+		fileSystem = OSFileSystem{}
+	}
+	return
+}
+
+// listGoFiles возвращает отсортированный список Go файлов директории
+func listGoFiles(fileSystem FileSystem, dir string) (files []string, err error) {
+
+	var entries []fs.DirEntry
+	if entries, err = fileSystem.ReadDir(dir); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("failed to read directory %s: %w", dir, err)
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return
+}
+
+// buildContextOf возвращает контекст сборки из данных pipeline, по умолчанию — build.Default
+func buildContextOf(data Data) (buildContext *build.Context) {
+
+	buildContext = data.Build
+	if buildContext == nil {
+		buildContext = &build.Default
+	}
+	return
+}
+
+// selectGoFiles возвращает Go файлы директории, которые go build включил бы в пакет для контекста сборки
+func selectGoFiles(fileSystem FileSystem, buildContext *build.Context, dir string) (files []string, err error) {
+
+	var candidates []string
+	if candidates, err = listGoFiles(fileSystem, dir); err != nil {
+		return
+	}
+	for _, file := range candidates {
+		var match bool
+		if match, err = matchGoFile(fileSystem, buildContext, file); err != nil {
+			return
+		}
+		if match {
+			files = append(files, file)
+		}
+	}
+	return
+}
+
+// matchGoFile проверяет, включил бы go build файл в пакет: _test.go файлы исключаются,
+// имя файла и ограничения //go:build проверяются для контекста сборки
+func matchGoFile(fileSystem FileSystem, buildContext *build.Context, file string) (match bool, err error) {
+
+	name := filepath.Base(file)
+	if strings.HasSuffix(name, "_test.go") {
+		return
+	}
+	// Заголовки файлов для проверки //go:build читаем через файловую систему pipeline
+	matchContext := *buildContext
+	matchContext.OpenFile = func(path string) (reader io.ReadCloser, err error) {
+
+		var content []byte
+		if content, err = fileSystem.ReadFile(path); err != nil {
+			return
+		}
+		reader = io.NopCloser(bytes.NewReader(content))
+		return
+	}
+	if match, err = matchContext.MatchFile(filepath.Dir(file), name); err != nil {
+		err = fmt.Errorf("failed to match build constraints of %s: %w", file, err)
+	}
+	return
+}
+
+// parseFile читает файл через файловую систему pipeline и парсит его
+func parseFile(fset *token.FileSet, fileSystem FileSystem, filename string, mode parser.Mode) (astFile *ast.File, err error) {
+
+	var content []byte
+	if content, err = fileSystem.ReadFile(filename); err != nil {
+		return
+	}
+	astFile, err = parser.ParseFile(fset, filename, content, mode)
+	return
+}
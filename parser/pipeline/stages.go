@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Имена встроенных этапов pipeline
+const (
+	StageNameModule        = "module"
+	StageNameAST           = "ast"
+	StageNameFilter        = "filter"
+	StageNameTypes         = "types"
+	StageNameTypeCheck     = "typecheck"
+	StageNameValidation    = "validation"
+	StageNameSchema        = "schema"
+	StageNameInheritance   = "inheritance"
+	StageNameSerialization = "serialization"
+)
+
+// NamedStage этап с именем, по которому его можно найти в pipeline
+type NamedStage interface {
+	Stage
+	Name() (name string)
+}
+
+// namedStage присваивает имя произвольному этапу
+type namedStage struct {
+	Stage
+	name string
+}
+
+func (s *namedStage) Name() (name string) {
+
+	name = s.name
+	return
+}
+
+// Named возвращает этап stage с именем name
+func Named(name string, stage Stage) (named NamedStage) {
+
+	named = &namedStage{Stage: stage, name: name}
+	return
+}
+
+// StageName возвращает имя этапа: результат Name() для NamedStage, иначе имя типа
+func StageName(stage Stage) (name string) {
+
+	if named, ok := stage.(NamedStage); ok {
+		name = named.Name()
+		return
+	}
+	name = strings.TrimPrefix(fmt.Sprintf("%T", stage), "*")
+	name = name[strings.LastIndex(name, ".")+1:]
+	return
+}
+
+// StageFunc позволяет использовать функцию как этап pipeline
+type StageFunc func(ctx context.Context, data Data) (result Data, err error)
+
+func (f StageFunc) Process(ctx context.Context, data Data) (result Data, err error) {
+
+	result, err = f(ctx, data)
+	return
+}
+
+// Stages возвращает копию списка этапов pipeline
+func (p *Pipeline) Stages() (stages []Stage) {
+
+	stages = append([]Stage(nil), p.stages...)
+	return
+}
+
+// indexOf возвращает индекс этапа с именем name или -1
+func (p *Pipeline) indexOf(name string) (index int) {
+
+	for i, stage := range p.stages {
+		if StageName(stage) == name {
+			index = i
+			return
+		}
+	}
+	index = -1
+	return
+}
+
+// HasStage сообщает, есть ли в pipeline этап с именем name
+func (p *Pipeline) HasStage(name string) (found bool) {
+
+	found = p.indexOf(name) != -1
+	return
+}
+
+// InsertBefore вставляет этап перед этапом с именем name
+func (p *Pipeline) InsertBefore(name string, stage Stage) (err error) {
+
+	index := p.indexOf(name)
+	if index == -1 {
+		err = fmt.Errorf("stage %q not found", name)
+		return
+	}
+	p.stages = append(p.stages[:index], append([]Stage{stage}, p.stages[index:]...)...)
+	return
+}
+
+// InsertAfter вставляет этап после этапа с именем name
+func (p *Pipeline) InsertAfter(name string, stage Stage) (err error) {
+
+	index := p.indexOf(name)
+	if index == -1 {
+		err = fmt.Errorf("stage %q not found", name)
+		return
+	}
+	p.stages = append(p.stages[:index+1], append([]Stage{stage}, p.stages[index+1:]...)...)
+	return
+}
+
+// RemoveStage удаляет этап с именем name
+func (p *Pipeline) RemoveStage(name string) (err error) {
+
+	index := p.indexOf(name)
+	if index == -1 {
+		err = fmt.Errorf("stage %q not found", name)
+		return
+	}
+	p.stages = append(p.stages[:index], p.stages[index+1:]...)
+	return
+}
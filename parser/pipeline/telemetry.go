@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName имя библиотеки для tracer и meter OpenTelemetry
+const InstrumentationName = "github.com/seniorGolang/asti"
+
+// Ключи атрибутов спанов pipeline
+const (
+	AttributePackagePath      = attribute.Key("asti.package.path")
+	AttributeStage            = attribute.Key("asti.stage")
+	AttributeFilesCount       = attribute.Key("asti.files.count")
+	AttributeInterfacesCount  = attribute.Key("asti.interfaces.count")
+	AttributeTypesCount       = attribute.Key("asti.types.count")
+	AttributeDiagnosticsCount = attribute.Key("asti.diagnostics.count")
+)
+
+// telemetry спаны и метрики выполнения этапов pipeline
+type telemetry struct {
+	tracer        trace.Tracer
+	stageDuration metric.Float64Histogram
+	stageErrors   metric.Int64Counter
+}
+
+func newTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (t *telemetry) {
+
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+	meter := meterProvider.Meter(InstrumentationName)
+	t = &telemetry{tracer: tracerProvider.Tracer(InstrumentationName)}
+	var err error
+	if t.stageDuration, err = meter.Float64Histogram("asti.stage.duration",
+		metric.WithDescription("Duration of a pipeline stage"), metric.WithUnit("s")); err != nil {
+		otel.Handle(err)
+	}
+	if t.stageErrors, err = meter.Int64Counter("asti.stage.errors",
+		metric.WithDescription("Number of failed pipeline stages")); err != nil {
+		otel.Handle(err)
+	}
+	return
+}
+
+// process выполняет этап внутри спана и записывает метрики
+func (t *telemetry) process(ctx context.Context, stage Stage, data Data) (result Data, err error) {
+
+	name := StageName(stage)
+	ctx, span := t.tracer.Start(ctx, "asti.stage "+name, trace.WithAttributes(AttributeStage.String(name)))
+	defer span.End()
+
+	started := time.Now()
+	result, err = stage.Process(ctx, data)
+	stageAttributes := metric.WithAttributes(AttributeStage.String(name))
+	if t.stageDuration != nil {
+		t.stageDuration.Record(ctx, time.Since(started).Seconds(), stageAttributes)
+	}
+	span.SetAttributes(DataAttributes(result)...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if t.stageErrors != nil {
+			t.stageErrors.Add(ctx, 1, stageAttributes)
+		}
+	}
+	return
+}
+
+// DataAttributes возвращает атрибуты спана, описывающие состояние данных pipeline
+func DataAttributes(data Data) (attributes []attribute.KeyValue) {
+
+	if data.Package != nil {
+		attributes = append(attributes, AttributePackagePath.String(data.Package.PackagePath))
+	}
+	if data.Files != nil {
+		attributes = append(attributes, AttributeFilesCount.Int(len(data.Files.Files)))
+	}
+	attributes = append(attributes,
+		AttributeInterfacesCount.Int(len(data.Interfaces)),
+		AttributeTypesCount.Int(len(data.Types)),
+		AttributeDiagnosticsCount.Int(len(data.Diagnostics)),
+	)
+	return
+}
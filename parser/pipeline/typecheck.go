@@ -0,0 +1,498 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+type StageTypeCheck struct{}
+
+func NewStageTypeCheck() (stage *StageTypeCheck) {
+
+	stage = &StageTypeCheck{}
+	return
+}
+
+func (s *StageTypeCheck) Name() (name string) {
+
+	name = StageNameTypeCheck
+	return
+}
+
+// Process уточняет типы интерфейсов и собранные типы по данным go/types
+func (s *StageTypeCheck) Process(ctx context.Context, data Data) (result Data, err error) {
+
+	if data.Package == nil {
+		err = fmt.Errorf("package data is required for type checking")
+		return
+	}
+	packagePath := absolutePackagePath(data)
+
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:     packagePath,
+	}
+	if buildContext := data.Build; buildContext != nil {
+		cfg.Env = append(os.Environ(), "GOOS="+buildContext.GOOS, "GOARCH="+buildContext.GOARCH)
+		if !buildContext.CgoEnabled {
+			cfg.Env = append(cfg.Env, "CGO_ENABLED=0")
+		}
+		if len(buildContext.BuildTags) > 0 {
+			cfg.BuildFlags = []string{"-tags=" + strings.Join(buildContext.BuildTags, ",")}
+		}
+	}
+	// go/packages читает файлы с диска, поэтому виртуальные источники поддерживаются только как overlay
+	switch fileSystem := data.FS.(type) {
+	case nil, OSFileSystem:
+	case *OverlayFileSystem:
+		if _, onDisk := fileSystem.base.(OSFileSystem); !onDisk {
+			err = fmt.Errorf("type checking requires package files on disk")
+			return
+		}
+		cfg.Overlay = fileSystem.files
+	default:
+		err = fmt.Errorf("type checking requires package files on disk")
+		return
+	}
+	var pkgs []*packages.Package
+	if pkgs, err = packages.Load(cfg, "."); err != nil {
+		err = fmt.Errorf("failed to load package for type checking: %w", err)
+		return
+	}
+	// Пустые директории обрабатываются без ошибки, как и на этапе AST
+	if len(pkgs) == 0 || len(pkgs[0].GoFiles) == 0 {
+		result = data
+		return
+	}
+	pkg := pkgs[0]
+	// Ошибки проверки типов сообщаем диагностиками и оставляем результат синтаксического разбора
+	if len(pkg.Errors) > 0 {
+		for _, pkgErr := range pkg.Errors {
+			data.Report(models.Diagnostic{
+				Severity: models.SeverityError,
+				Code:     models.CodeTypeCheck,
+				Message:  pkgErr.Msg,
+				Position: positionFromString(pkgErr.Pos, packagePath),
+			})
+		}
+		result = data
+		return
+	}
+
+	checker := &typeChecker{
+		pkg:         pkg.Types,
+		fset:        pkg.Fset,
+		packagePath: packagePath,
+		syntactic:   data.Types,
+		types:       make(map[string]models.TypeInfo),
+		visited:     make(map[*types.TypeName]bool),
+	}
+	for i := range data.Interfaces {
+		checker.refineInterface(&data.Interfaces[i])
+	}
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		if typeName, ok := scope.Lookup(name).(*types.TypeName); ok {
+			checker.collect(typeName)
+		}
+	}
+	data.Types = checker.types
+	result = data
+	return
+}
+
+// typeChecker хранит состояние одного прохода уточнения типов
+type typeChecker struct {
+	pkg         *types.Package
+	fset        *token.FileSet
+	packagePath string
+	syntactic   map[string]models.TypeInfo
+	types       map[string]models.TypeInfo
+	visited     map[*types.TypeName]bool
+}
+
+// typeShape описывает модификаторы типа (указатель, срез, карта и т.д.) вокруг базового типа
+type typeShape struct {
+	base     types.Type
+	pointer  bool
+	slice    bool
+	array    bool
+	arrayLen int
+	mapped   bool
+	channel  bool
+	generic  bool
+}
+
+func (c *typeChecker) qualifier(pkg *types.Package) (name string) {
+
+	name = pkg.Name()
+	return
+}
+
+func (c *typeChecker) typeString(t types.Type) (typeStr string) {
+
+	typeStr = types.TypeString(t, c.qualifier)
+	return
+}
+
+func (c *typeChecker) typeKey(obj *types.TypeName) (key string) {
+
+	key = c.qualifier(obj.Pkg()) + "." + obj.Name()
+	return
+}
+
+func (c *typeChecker) position(pos token.Pos) (position models.Position) {
+
+	p := c.fset.Position(pos)
+	relativePath, err := filepath.Rel(c.packagePath, p.Filename)
+	if err != nil {
+		relativePath = p.Filename
+	}
+	position = models.Position{
+		File:   relativePath,
+		Line:   p.Line,
+		Column: p.Column,
+	}
+	return
+}
+
+// shapeOf снимает с типа модификаторы так же, как analyzeTypeCharacteristics делает это для AST
+func (c *typeChecker) shapeOf(t types.Type) (shape typeShape) {
+
+	for {
+		t = types.Unalias(t)
+		switch u := t.(type) {
+		case *types.Pointer:
+			shape.pointer = true
+			t = u.Elem()
+		case *types.Slice:
+			shape.slice = true
+			t = u.Elem()
+		case *types.Array:
+			shape.array = true
+			shape.arrayLen = int(u.Len())
+			t = u.Elem()
+		case *types.Map:
+			shape.mapped = true
+			t = u.Elem()
+		case *types.Chan:
+			shape.channel = true
+			t = u.Elem()
+		case *types.Named:
+			shape.generic = u.TypeArgs().Len() > 0
+			shape.base = u
+			return
+		default:
+			shape.base = t
+			return
+		}
+	}
+}
+
+// baseTypeString возвращает имя базового типа без аргументов дженерика
+func (c *typeChecker) baseTypeString(t types.Type) (typeStr string) {
+
+	if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil {
+		typeStr = c.typeKey(named.Origin().Obj())
+		return
+	}
+	typeStr = c.typeString(t)
+	return
+}
+
+func (c *typeChecker) refineInterface(iface *models.Interface) {
+
+	typeName, ok := c.pkg.Scope().Lookup(iface.Name).(*types.TypeName)
+	if !ok {
+		return
+	}
+	ifaceType, ok := typeName.Type().Underlying().(*types.Interface)
+	if !ok {
+		return
+	}
+	for i := range iface.Methods {
+		method := &iface.Methods[i]
+		for j := 0; j < ifaceType.NumMethods(); j++ {
+			fn := ifaceType.Method(j)
+			if fn.Name() != method.Name {
+				continue
+			}
+			signature := fn.Type().(*types.Signature)
+			c.refineVariables(method.Parameters, signature.Params(), signature.Variadic())
+			c.refineVariables(method.Results, signature.Results(), false)
+			break
+		}
+	}
+}
+
+func (c *typeChecker) refineVariables(variables []models.Variable, tuple *types.Tuple, variadic bool) {
+
+	if tuple.Len() != len(variables) {
+		return
+	}
+	for i := range variables {
+		annotations, namespaces := variables[i].Annotations, variables[i].Namespaces
+		variables[i] = c.variable(variables[i].Name, tuple.At(i).Type(), variadic && i == tuple.Len()-1)
+		variables[i].Annotations, variables[i].Namespaces = annotations, namespaces
+		c.collectType(tuple.At(i).Type())
+	}
+}
+
+func (c *typeChecker) variable(name string, t types.Type, variadic bool) (variable models.Variable) {
+
+	if variadic {
+		if slice, ok := t.(*types.Slice); ok {
+			t = slice.Elem()
+		}
+	}
+	shape := c.shapeOf(t)
+	variable = models.Variable{
+		Name:     name,
+		Type:     c.baseTypeString(shape.base),
+		Variadic: variadic,
+		Pointer:  shape.pointer,
+		Slice:    shape.slice,
+		Map:      shape.mapped,
+		Channel:  shape.channel,
+		Generic:  shape.generic,
+		Array:    shape.array,
+		ArrayLen: shape.arrayLen,
+	}
+	return
+}
+
+// collectType добавляет в результат все именованные типы, на которые ссылается t
+func (c *typeChecker) collectType(t types.Type) {
+
+	switch u := types.Unalias(t).(type) {
+	case *types.Pointer:
+		c.collectType(u.Elem())
+	case *types.Slice:
+		c.collectType(u.Elem())
+	case *types.Array:
+		c.collectType(u.Elem())
+	case *types.Map:
+		c.collectType(u.Key())
+		c.collectType(u.Elem())
+	case *types.Chan:
+		c.collectType(u.Elem())
+	case *types.Named:
+		c.collect(u.Origin().Obj())
+		for i := 0; i < u.TypeArgs().Len(); i++ {
+			c.collectType(u.TypeArgs().At(i))
+		}
+	}
+}
+
+func (c *typeChecker) collect(obj *types.TypeName) {
+
+	if obj.Pkg() == nil || c.visited[obj] {
+		return
+	}
+	c.visited[obj] = true
+	key := c.typeKey(obj)
+	typeInfo := c.typeInfo(obj, c.syntactic[key])
+	c.types[key] = typeInfo
+	// Зависимости собираем только для типов текущего пакета, внешние типы описываются без полей
+	if obj.Pkg() != c.pkg {
+		return
+	}
+	if structType, ok := obj.Type().Underlying().(*types.Struct); ok {
+		for i := 0; i < structType.NumFields(); i++ {
+			c.collectType(structType.Field(i).Type())
+		}
+	}
+}
+
+func (c *typeChecker) typeInfo(obj *types.TypeName, syntactic models.TypeInfo) (typeInfo models.TypeInfo) {
+
+	own := obj.Pkg() == c.pkg
+	typeInfo = models.TypeInfo{
+		Name:    obj.Name(),
+		Package: obj.Pkg().Name(),
+		Import:  obj.Pkg().Path(),
+	}
+	if own {
+		typeInfo.Position = c.position(obj.Pos())
+		typeInfo.Annotations = syntactic.Annotations
+		typeInfo.Namespaces = syntactic.Namespaces
+	}
+	if obj.IsAlias() {
+		typeInfo.Kind = models.TypeAlias
+		typeInfo.Underlying = c.typeString(types.Unalias(obj.Type()))
+		return
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		typeInfo.Kind = models.TypeBasic
+		return
+	}
+	if typeParams := named.TypeParams(); typeParams.Len() > 0 {
+		typeInfo.GenericType = true
+		typeInfo.Generic = &models.GenericInfo{}
+		for i := 0; i < typeParams.Len(); i++ {
+			typeInfo.Generic.TypeParams = append(typeInfo.Generic.TypeParams, typeParams.At(i).Obj().Name())
+			typeInfo.Generic.Constraints = append(typeInfo.Generic.Constraints, c.typeString(typeParams.At(i).Constraint()))
+		}
+	}
+	switch u := named.Underlying().(type) {
+	case *types.Struct:
+		typeInfo.Kind = models.TypeStruct
+		if own {
+			typeInfo.Fields = c.fields(u, syntactic.Fields)
+		}
+	case *types.Interface:
+		typeInfo.Kind = models.TypeInterface
+		typeInfo.Interface = true
+		if own {
+			for i := 0; i < u.NumMethods(); i++ {
+				typeInfo.Methods = append(typeInfo.Methods, c.methodInfo(u.Method(i)))
+			}
+		}
+	case *types.Slice:
+		typeInfo.Kind = models.TypeSlice
+		typeInfo.Slice = true
+	case *types.Array:
+		typeInfo.Kind = models.TypeArray
+		typeInfo.Array = true
+		typeInfo.ArrayLen = int(u.Len())
+	case *types.Map:
+		typeInfo.Kind = models.TypeMap
+		typeInfo.Map = true
+	case *types.Chan:
+		typeInfo.Kind = models.TypeChannel
+		typeInfo.Channel = true
+	case *types.Signature:
+		typeInfo.Kind = models.TypeFunction
+		typeInfo.Function = true
+	case *types.Pointer:
+		typeInfo.Kind = models.TypePointer
+		typeInfo.Pointer = true
+	default:
+		typeInfo.Kind = models.TypeBasic
+		if own {
+			typeInfo.Constants = c.constants(named)
+			if len(typeInfo.Constants) > 0 {
+				typeInfo.Kind = models.TypeEnum
+			}
+		}
+	}
+	if typeInfo.Kind != models.TypeStruct && typeInfo.Kind != models.TypeInterface {
+		typeInfo.Underlying = c.typeString(named.Underlying())
+	}
+	if own && typeInfo.Kind != models.TypeInterface {
+		for i := 0; i < named.NumMethods(); i++ {
+			typeInfo.Methods = append(typeInfo.Methods, c.methodInfo(named.Method(i)))
+		}
+	}
+	return
+}
+
+func (c *typeChecker) fields(structType *types.Struct, syntactic []models.FieldInfo) (fields []models.FieldInfo) {
+
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		shape := c.shapeOf(field.Type())
+		fieldInfo := models.FieldInfo{
+			Name:     field.Name(),
+			Type:     c.typeString(field.Type()),
+			Position: c.position(field.Pos()),
+			Embedded: field.Embedded(),
+			Pointer:  shape.pointer,
+			Slice:    shape.slice,
+			Map:      shape.mapped,
+			Channel:  shape.channel,
+			Generic:  shape.generic,
+			Array:    shape.array,
+			ArrayLen: shape.arrayLen,
+		}
+		if tag := structType.Tag(i); tag != "" {
+			fieldInfo.Tags = parseTags(tag)
+		}
+		// Аннотации известны только из AST, поля совпадают по порядку объявления
+		if len(syntactic) == structType.NumFields() {
+			fieldInfo.Annotations = syntactic[i].Annotations
+			fieldInfo.Namespaces = syntactic[i].Namespaces
+		}
+		fields = append(fields, fieldInfo)
+	}
+	return
+}
+
+func (c *typeChecker) methodInfo(fn *types.Func) (method models.MethodInfo) {
+
+	signature := fn.Type().(*types.Signature)
+	method = models.MethodInfo{
+		Name:     fn.Name(),
+		Position: c.position(fn.Pos()),
+	}
+	for i := 0; i < signature.Params().Len(); i++ {
+		param := signature.Params().At(i)
+		variadic := signature.Variadic() && i == signature.Params().Len()-1
+		method.Parameters = append(method.Parameters, c.variable(param.Name(), param.Type(), variadic))
+	}
+	for i := 0; i < signature.Results().Len(); i++ {
+		result := signature.Results().At(i)
+		method.Results = append(method.Results, c.variable(result.Name(), result.Type(), false))
+	}
+	return
+}
+
+// constants возвращает константы пакета с указанным именованным типом в порядке объявления
+func (c *typeChecker) constants(named *types.Named) (constants []models.ConstantInfo) {
+
+	var declared []*types.Const
+	scope := c.pkg.Scope()
+	for _, name := range scope.Names() {
+		if constant, ok := scope.Lookup(name).(*types.Const); ok && types.Identical(constant.Type(), named) {
+			declared = append(declared, constant)
+		}
+	}
+	sort.Slice(declared, func(i, j int) bool { return declared[i].Pos() < declared[j].Pos() })
+	for _, constant := range declared {
+		constants = append(constants, models.ConstantInfo{
+			Name:  constant.Name(),
+			Value: constant.Val().ExactString(),
+			Type:  c.typeKey(named.Obj()),
+		})
+	}
+	return
+}
+
+// positionFromString разбирает позицию go/packages вида file:line:column
+func positionFromString(pos string, packagePath string) (position models.Position) {
+
+	parts := strings.Split(pos, ":")
+	numbers := make([]int, 0, 2)
+	for len(parts) > 1 && len(numbers) < 2 {
+		number, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		numbers = append([]int{number}, numbers...)
+		parts = parts[:len(parts)-1]
+	}
+	position.File = strings.Join(parts, ":")
+	if relativePath, err := filepath.Rel(packagePath, position.File); err == nil && filepath.IsAbs(position.File) {
+		position.File = relativePath
+	}
+	if len(numbers) > 0 {
+		position.Line = numbers[0]
+	}
+	if len(numbers) > 1 {
+		position.Column = numbers[1]
+	}
+	return
+}
@@ -0,0 +1,135 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+func TestStageTypeCheck_Process(t *testing.T) {
+	// Создаем временный модуль с двумя пакетами
+	tempDir, err := os.MkdirTemp("", "typecheck_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	files := map[string]string{
+		"go.mod": "module github.com/test/typecheck\n\ngo 1.24\n",
+		"dto/dto.go": `package dto
+
+type User struct {
+	Name string
+}
+`,
+		"service/service.go": `package service
+
+import (
+	"context"
+	stdtime "time"
+
+	. "github.com/test/typecheck/dto"
+)
+
+type Status string
+
+const (
+	StatusActive  Status = "active"
+	StatusBlocked Status = "blocked"
+)
+
+type Moment = stdtime.Time
+
+// @asti name=UserService
+type UserService interface {
+	// @asti method=GetUser
+	GetUser(ctx context.Context, ids ...string) (user *User, status Status, at Moment, err error)
+}
+
+type Account struct {
+	Owner  User ` + "`json:\"owner\"`" + `
+	Status Status
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	annotationParser := models.NewAnnotationParser("@asti")
+	pipeline := NewPipeline(
+		NewStageModule(),
+		NewStageAST(annotationParser),
+		NewStageTypeCollection(annotationParser),
+		NewStageTypeCheck(),
+	)
+	result, err := pipeline.Execute(context.Background(), Data{
+		Package: &models.Package{PackagePath: filepath.Join(tempDir, "service")},
+	})
+	if err != nil {
+		t.Fatalf("Pipeline execution failed: %v", err)
+	}
+
+	if len(result.Interfaces) != 1 || len(result.Interfaces[0].Methods) != 1 {
+		t.Fatalf("Expected one interface with one method, got %+v", result.Interfaces)
+	}
+	method := result.Interfaces[0].Methods[0]
+
+	expectedParams := []models.Variable{
+		{Name: "ctx", Type: "context.Context"},
+		{Name: "ids", Type: "string", Variadic: true},
+	}
+	expectedResults := []models.Variable{
+		{Name: "user", Type: "dto.User", Pointer: true},
+		{Name: "status", Type: "service.Status"},
+		{Name: "at", Type: "time.Time"},
+		{Name: "err", Type: "error"},
+	}
+	for i, expected := range expectedParams {
+		if !reflect.DeepEqual(method.Parameters[i], expected) {
+			t.Errorf("Parameter %d: expected %+v, got %+v", i, expected, method.Parameters[i])
+		}
+	}
+	for i, expected := range expectedResults {
+		if !reflect.DeepEqual(method.Results[i], expected) {
+			t.Errorf("Result %d: expected %+v, got %+v", i, expected, method.Results[i])
+		}
+	}
+
+	// Тип из dot-импорта должен ссылаться на свой пакет, а не на текущий
+	user, found := result.Types["dto.User"]
+	if !found {
+		t.Fatal("Type dto.User not found")
+	}
+	if user.Import != "github.com/test/typecheck/dto" || user.Kind != models.TypeStruct {
+		t.Errorf("Unexpected dto.User info: %+v", user)
+	}
+
+	status := result.Types["service.Status"]
+	if status.Kind != models.TypeEnum || status.Underlying != "string" || len(status.Constants) != 2 {
+		t.Errorf("Expected service.Status to be enum over string with 2 constants, got %+v", status)
+	}
+	if status.Constants[0].Name != "StatusActive" {
+		t.Errorf("Expected constants in declaration order, got %+v", status.Constants)
+	}
+
+	moment := result.Types["service.Moment"]
+	if moment.Kind != models.TypeAlias || moment.Underlying != "time.Time" {
+		t.Errorf("Expected service.Moment to be alias of time.Time, got %+v", moment)
+	}
+
+	account := result.Types["service.Account"]
+	if len(account.Fields) != 2 || account.Fields[0].Type != "dto.User" || account.Fields[0].Tags["json"] != "owner" {
+		t.Errorf("Unexpected service.Account fields: %+v", account.Fields)
+	}
+}
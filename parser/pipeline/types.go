@@ -4,31 +4,51 @@ import (
 	"context"
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
 	"path/filepath"
 	"strings"
-	"sync"
 
 	"github.com/seniorGolang/asti/parser/models"
 )
 
 type StageTypeCollection struct {
-	annotationParser models.AnnotationParser
-	packageInfo      *models.Package
-	imports          map[string]string // alias -> full path
-	importsMutex     sync.RWMutex
+	annotationReader
 }
 
-func NewStageTypeCollection(annotationParser models.AnnotationParser) (stage *StageTypeCollection) {
+// typeCollection состояние одного вызова StageTypeCollection.Process, благодаря которому этап
+// можно безопасно использовать из нескольких горутин
+type typeCollection struct {
+	*StageTypeCollection
+	packageInfo   *models.Package
+	files         *PackageFiles
+	declaredTypes map[string]models.TypeInfo // типы, объявленные в пакете
+	imports       map[string]string          // alias -> full path
+	diagnostics   []models.Diagnostic
+}
+
+// NewStageTypeCollection создает этап сбора типов; пространства имен namespaces разбираются как в NewStageAST
+func NewStageTypeCollection(annotationParser models.AnnotationParser, namespaces ...models.AnnotationNamespace) (stage *StageTypeCollection) {
+
+	stage = &StageTypeCollection{annotationReader: annotationReader{annotationParser: annotationParser, namespaces: namespaces}}
+	return
+}
+
+func (s *StageTypeCollection) Name() (name string) {
 
-	stage = &StageTypeCollection{annotationParser: annotationParser}
+	name = StageNameTypes
 	return
 }
 
 // Process выполняет сбор типов
 func (s *StageTypeCollection) Process(ctx context.Context, data Data) (result Data, err error) {
 
+	collection := &typeCollection{StageTypeCollection: s}
+	result, err = collection.process(ctx, data)
+	return
+}
+
+func (s *typeCollection) process(ctx context.Context, data Data) (result Data, err error) {
+
 	// Package должен быть уже инициализирован предыдущими этапами
 	if data.Package == nil {
 		err = fmt.Errorf("package data is required for type collection")
@@ -45,33 +65,27 @@ func (s *StageTypeCollection) Process(ctx context.Context, data Data) (result Da
 	s.packageInfo = data.Package
 
 	// Получаем абсолютный путь для поиска файлов
-	actualPackagePath := data.Package.PackagePath
-	if data.Annotations != nil {
-		if absPathData, exists := data.Annotations["_absolutePackagePath"]; exists {
-			if absPath, ok := absPathData["path"]; ok {
-				actualPackagePath = absPath
-			}
-		}
+	actualPackagePath := absolutePackagePath(data)
+
+	if s.files, err = packageFilesOf(ctx, &data, actualPackagePath); err != nil {
+		return
 	}
 
 	// Собираем информацию об импортах
 	s.imports = make(map[string]string)
-	s.collectImports(actualPackagePath)
+	s.collectImports()
 
 	// Сначала собираем все типы из файлов
-	pattern := filepath.Join(actualPackagePath, "*.go")
-	files, err := filepath.Glob(pattern)
-	if err == nil {
-		fset := token.NewFileSet()
-		for _, filename := range files {
-			astFile, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
-			if err == nil {
-				types, err := s.extractFromFile(context.Background(), astFile, fset, filename, actualPackagePath)
-				if err == nil {
-					for key, typeInfo := range types {
-						allTypes[key] = typeInfo
-					}
-				}
+	s.declaredTypes = make(map[string]models.TypeInfo)
+	for _, file := range s.files.Files {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		types, err := s.extractFromFile(ctx, file.AST, s.files.Fset, file.Path, actualPackagePath)
+		if err == nil {
+			for key, typeInfo := range types {
+				allTypes[key] = typeInfo
+				s.declaredTypes[key] = typeInfo
 			}
 		}
 	}
@@ -89,13 +103,16 @@ func (s *StageTypeCollection) Process(ctx context.Context, data Data) (result Da
 		}
 	}
 
+	for _, diagnostic := range s.diagnostics {
+		data.Report(diagnostic)
+	}
 	data.Types = allTypes
 	result = data
 	return
 }
 
 // collectTypeRecursively собирает тип и все его зависимости рекурсивно
-func (s *StageTypeCollection) collectTypeRecursively(typeStr string, packagePath string, usedTypes map[string]models.TypeInfo, processedTypes map[string]bool) {
+func (s *typeCollection) collectTypeRecursively(typeStr string, packagePath string, usedTypes map[string]models.TypeInfo, processedTypes map[string]bool) {
 	baseType := s.getBaseType(typeStr)
 	if s.isBasicType(baseType) {
 		return
@@ -110,7 +127,7 @@ func (s *StageTypeCollection) collectTypeRecursively(typeStr string, packagePath
 	processedTypes[baseType] = true
 
 	// Ищем тип в файлах пакета
-	typeInfo, found := s.findTypeInPackage(baseType, packagePath)
+	typeInfo, found := s.findTypeInPackage(baseType)
 	if !found {
 		// Если тип не найден в пакете, возможно это импортированный тип
 		// Создаем базовую информацию для него
@@ -130,11 +147,9 @@ func (s *StageTypeCollection) collectTypeRecursively(typeStr string, packagePath
 			}
 		} else {
 			// Если пакет указан, ищем его в импортах
-			s.importsMutex.RLock()
 			if path, exists := s.imports[packageName]; exists {
 				importPath = path
 			}
-			s.importsMutex.RUnlock()
 		}
 
 		// Для импортированных типов используем короткое имя пакета
@@ -173,44 +188,14 @@ func (s *StageTypeCollection) collectTypeRecursively(typeStr string, packagePath
 	}
 }
 
-// findTypeInPackage ищет тип в файлах пакета
-func (s *StageTypeCollection) findTypeInPackage(typeName string, packagePath string) (typeInfo models.TypeInfo, found bool) {
-	// Получаем все Go файлы в пакете
-	pattern := filepath.Join(packagePath, "*.go")
-	files, err := filepath.Glob(pattern)
-	if err != nil {
-		return
-	}
-
-	fset := token.NewFileSet()
-	for _, filename := range files {
-		astFile, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
-		if err != nil {
-			continue
-		}
-
-		// Ищем тип в этом файле
-		types, err := s.extractFromFile(context.Background(), astFile, fset, filename, packagePath)
-		if err != nil {
-			continue
-		}
+// findTypeInPackage ищет тип среди объявленных в пакете
+func (s *typeCollection) findTypeInPackage(typeName string) (typeInfo models.TypeInfo, found bool) {
 
-		// Ищем тип по имени
-		for key, info := range types {
-			if s.getTypeName(key) == typeName {
-				typeInfo = info
-				found = true
-				return
-			}
-			// Также ищем по полному ключу, если typeName содержит точку
-			if key == typeName {
-				typeInfo = info
-				found = true
-				return
-			}
-		}
+	key := typeName
+	if !strings.Contains(typeName, ".") {
+		key = s.files.Name + "." + typeName
 	}
-
+	typeInfo, found = s.declaredTypes[key]
 	return
 }
 
@@ -270,26 +255,6 @@ func (s *StageTypeCollection) isBasicType(typeStr string) (isBasic bool) {
 	return
 }
 
-func (s *StageTypeCollection) hasAnnotation(commentText string) (hasAnnotation bool) {
-
-	commentText = strings.TrimSpace(commentText)
-	if !strings.HasPrefix(commentText, "//") {
-		hasAnnotation = false
-		return
-	}
-	content := strings.TrimSpace(strings.TrimPrefix(commentText, "//"))
-	if !strings.HasPrefix(content, "@") {
-		hasAnnotation = false
-		return
-	}
-	prefix := strings.TrimPrefix(s.annotationParser.(*models.DefaultAnnotationParser).GetPrefix(), "@")
-	hasAnnotation = strings.HasPrefix(content[1:], prefix)
-	
-
-	
-	return
-}
-
 func (s *StageTypeCollection) getTypeName(fullType string) (typeName string) {
 
 	if idx := strings.LastIndex(fullType, "."); idx != -1 {
@@ -322,7 +287,7 @@ func (s *StageTypeCollection) getPackageNameFromPath(packagePath string) (packag
 	return
 }
 
-func (s *StageTypeCollection) extractFromFile(ctx context.Context, astFile *ast.File, fset *token.FileSet, filename string, packagePath string) (types map[string]models.TypeInfo, err error) {
+func (s *typeCollection) extractFromFile(ctx context.Context, astFile *ast.File, fset *token.FileSet, filename string, packagePath string) (types map[string]models.TypeInfo, err error) {
 
 	types = make(map[string]models.TypeInfo)
 	for _, decl := range astFile.Decls {
@@ -396,22 +361,7 @@ func (s *StageTypeCollection) extractFromFile(ctx context.Context, astFile *ast.
 							}
 						}
 					}
-					if genDecl.Doc != nil {
-						for _, comment := range genDecl.Doc.List {
-							if s.hasAnnotation(comment.Text) {
-								var annotations models.Annotations
-								annotations, err = s.annotationParser.Parse(ctx, comment.Text)
-								if err == nil {
-									if typeInfo.Annotations == nil {
-										typeInfo.Annotations = make(models.Annotations)
-									}
-									for k, v := range annotations {
-										typeInfo.Annotations[k] = v
-									}
-								}
-							}
-						}
-					}
+					typeInfo.Annotations, typeInfo.Namespaces = s.read(ctx, genDecl.Doc, fset, packagePath, &s.diagnostics)
 					types[fullTypeName] = typeInfo
 				}
 			}
@@ -420,7 +370,7 @@ func (s *StageTypeCollection) extractFromFile(ctx context.Context, astFile *ast.
 	return
 }
 
-func (s *StageTypeCollection) extractFields(ctx context.Context, structType *ast.StructType, fset *token.FileSet, filename string, packagePath string) (fields []models.FieldInfo) {
+func (s *typeCollection) extractFields(ctx context.Context, structType *ast.StructType, fset *token.FileSet, filename string, packagePath string) (fields []models.FieldInfo) {
 
 	if structType.Fields == nil {
 		return fields
@@ -445,25 +395,10 @@ func (s *StageTypeCollection) extractFields(ctx context.Context, structType *ast
 						Column: pos.Column,
 					},
 				}
-				if field.Doc != nil {
-					for _, comment := range field.Doc.List {
-						if s.hasAnnotation(comment.Text) {
-							var annotations models.Annotations
-							annotations, err = s.annotationParser.Parse(ctx, comment.Text)
-							if err == nil {
-								if fieldInfo.Annotations == nil {
-									fieldInfo.Annotations = make(models.Annotations)
-								}
-								for k, v := range annotations {
-									fieldInfo.Annotations[k] = v
-								}
-							}
-						}
-					}
-				}
+				fieldInfo.Annotations, fieldInfo.Namespaces = s.read(ctx, field.Doc, fset, packagePath, &s.diagnostics)
 				s.analyzeFieldType(field.Type, &fieldInfo)
 				if field.Tag != nil {
-					fieldInfo.Tags = s.parseTags(field.Tag.Value)
+					fieldInfo.Tags = parseTags(field.Tag.Value)
 				}
 				fields = append(fields, fieldInfo)
 			}
@@ -492,7 +427,7 @@ func (s *StageTypeCollection) extractFields(ctx context.Context, structType *ast
 	return
 }
 
-func (s *StageTypeCollection) typeToString(expr ast.Expr) (typeStr string) {
+func (s *typeCollection) typeToString(expr ast.Expr) (typeStr string) {
 
 	switch t := expr.(type) {
 	case *ast.Ident:
@@ -501,9 +436,7 @@ func (s *StageTypeCollection) typeToString(expr ast.Expr) (typeStr string) {
 			typeStr = t.Name
 		} else {
 			// Проверяем, есть ли импорт с таким именем
-			s.importsMutex.RLock()
 			importPath, hasImport := s.imports[t.Name]
-			s.importsMutex.RUnlock()
 			
 			if hasImport {
 				// Это тип из внешнего пакета
@@ -580,7 +513,8 @@ func (s *StageTypeCollection) analyzeFieldType(expr ast.Expr, field *models.Fiel
 	}
 }
 
-func (s *StageTypeCollection) parseTags(tagValue string) (tags map[string]string) {
+// parseTags разбирает тег поля структуры в карту ключ-значение
+func parseTags(tagValue string) (tags map[string]string) {
 
 	tags = make(map[string]string)
 	tagValue = strings.Trim(tagValue, "`")
@@ -596,20 +530,10 @@ func (s *StageTypeCollection) parseTags(tagValue string) (tags map[string]string
 }
 
 // collectImports собирает информацию об импортах из всех файлов пакета
-func (s *StageTypeCollection) collectImports(packagePath string) {
-	// Получаем все Go файлы в пакете
-	pattern := filepath.Join(packagePath, "*.go")
-	files, err := filepath.Glob(pattern)
-	if err != nil {
-		return
-	}
+func (s *typeCollection) collectImports() {
 
-	fset := token.NewFileSet()
-	for _, filename := range files {
-		astFile, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
-		if err != nil {
-			continue
-		}
+	for _, file := range s.files.Files {
+		astFile := file.AST
 
 		// Обрабатываем импорты
 		for _, decl := range astFile.Decls {
@@ -629,9 +553,7 @@ func (s *StageTypeCollection) collectImports(packagePath string) {
 							alias = parts[len(parts)-1]
 						}
 						
-						s.importsMutex.Lock()
 						s.imports[alias] = importPath
-						s.importsMutex.Unlock()
 					}
 				}
 			}
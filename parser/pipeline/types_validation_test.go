@@ -53,13 +53,6 @@ func TestTypeInfoPackageFieldForExternalTypes(t *testing.T) {
 		},
 	}
 
-	// Симулируем импорты
-	stage.imports = map[string]string{
-		"time":    "time",
-		"strings": "strings",
-		"json":    "encoding/json",
-	}
-
 	// Выполняем обработку
 	result, err := stage.Process(context.Background(), data)
 	if err != nil {
@@ -97,14 +90,6 @@ func TestTypeInfoImportFieldValidation(t *testing.T) {
 		},
 	}
 
-	// Симулируем импорты с полными путями
-	stage.imports = map[string]string{
-		"time":    "time",
-		"strings": "strings",
-		"json":    "encoding/json",
-		"external": "github.com/external/package",
-	}
-
 	// Выполняем обработку
 	result, err := stage.Process(context.Background(), data)
 	if err != nil {
@@ -25,16 +25,26 @@ func NewStageValidation(rules ...ValidationRule) (stage *StageValidation) {
 	return
 }
 
+func (s *StageValidation) Name() (name string) {
+
+	name = StageNameValidation
+	return
+}
+
 // Process выполняет валидацию интерфейсов
 func (s *StageValidation) Process(ctx context.Context, data Data) (result Data, err error) {
 
-	var errors []error
 	var validInterfaces []models.Interface
 	for _, iface := range data.Interfaces {
 		isValid := true
 		for _, rule := range s.rules {
-			if err = rule.Validate(iface); err != nil {
-				errors = append(errors, fmt.Errorf("validation failed for interface %s: %w", iface.Name, err))
+			if ruleErr := rule.Validate(iface); ruleErr != nil {
+				data.Report(models.Diagnostic{
+					Severity: models.SeverityError,
+					Code:     models.CodeValidation,
+					Message:  fmt.Sprintf("validation failed for interface %s: %v", iface.Name, ruleErr),
+					Position: iface.Position,
+				})
 				isValid = false
 				break
 			}
@@ -44,7 +54,7 @@ func (s *StageValidation) Process(ctx context.Context, data Data) (result Data,
 		}
 	}
 	data.Interfaces = validInterfaces
-	data.Errors = append(data.Errors, errors...)
+	result = data
 	return
 }
 
@@ -52,7 +62,7 @@ type AnnotationRule struct{}
 
 func (r *AnnotationRule) Validate(iface models.Interface) (err error) {
 
-	if len(iface.Annotations) == 0 {
+	if len(iface.Annotations) == 0 && len(iface.Namespaces) == 0 {
 		err = fmt.Errorf("interface must have annotations")
 		return
 	}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+const schemaServiceFile = `package service
+
+import "context"
+
+// @asti name=OrderService timout=10 transport=grpc
+type OrderService interface {
+	// @asti method=Create http=true grpc=true
+	Create(ctx context.Context, order Order) (err error)
+}
+
+// @asti table=orders table=items
+type Order struct {
+	// @asti column=id
+	ID string
+}
+`
+
+const schemaJSON = `{
+  "targets": {
+    "interface": {
+      "keys": [
+        {"name": "name", "required": true},
+        {"name": "timeout", "kind": "duration"},
+        {"name": "transport", "enum": ["http", "jsonrpc"]},
+        {"name": "version", "required": true}
+      ]
+    },
+    "method": {
+      "keys": [
+        {"name": "method"},
+        {"name": "http", "kind": "bool"},
+        {"name": "grpc", "kind": "bool"}
+      ],
+      "exclusive": [["http", "grpc"]]
+    },
+    "type": {
+      "keys": [{"name": "table"}]
+    }
+  }
+}`
+
+const schemaUnannotatedFile = `package service
+
+import "context"
+
+// @asti name=OrderService
+type OrderService interface {
+	// @asti method=Create
+	Create(ctx context.Context) (err error)
+	Cancel(ctx context.Context) (err error)
+}
+
+type Plain struct {
+	ID string
+}
+`
+
+// TestAnnotationSchema проверяет схемы из JSON и Go кода, их проверку при разборе и диагностики этапа проверки
+func TestAnnotationSchema(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, []byte(schemaJSON), 0600); err != nil {
+		t.Fatalf("Failed to write schema: %v", err)
+	}
+	loaded, err := models.LoadAnnotationSchema(path)
+	if err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		src      string
+		schema   *models.AnnotationSchema
+		expected []string
+		err      string
+	}{
+		{
+			name:   "json",
+			src:    schemaServiceFile,
+			schema: loaded,
+			expected: []string{
+				`service.go:5:35: error: interface OrderService: unknown annotation "timout", did you mean "timeout"? [annotation-schema]`,
+				`service.go:5:48: error: interface OrderService: annotation transport: value "grpc" is not one of http, jsonrpc [annotation-schema]`,
+				`service.go:6:6: error: interface OrderService: missing required annotation "version" [annotation-schema]`,
+				`service.go:7:40: error: method OrderService.Create: annotations http, grpc are mutually exclusive [annotation-schema]`,
+				`service.go:11:29: error: type service.Order: annotation table is repeated [annotation-schema]`,
+			},
+		},
+		{
+			name: "required unannotated",
+			src:  schemaUnannotatedFile,
+			schema: &models.AnnotationSchema{Targets: map[models.Target]models.TargetSchema{
+				models.TargetMethod: {Keys: []models.KeySchema{{Name: "method", Required: true}}},
+				models.TargetField:  {Keys: []models.KeySchema{{Name: "column", Required: true}}},
+			}},
+			expected: []string{
+				`service.go:9:2: error: method OrderService.Cancel: missing required annotation "method" [annotation-schema]`,
+			},
+		},
+		{
+			name: "unknown kind",
+			src:  schemaServiceFile,
+			schema: &models.AnnotationSchema{Targets: map[models.Target]models.TargetSchema{
+				models.TargetInterface: {Keys: []models.KeySchema{{Name: "port", Kind: "integer"}}},
+			}},
+			err: `unknown kind "integer"`,
+		},
+	} {
+		result, err := NewParser(WithAnnotationSchema(test.schema)).ParseSources(context.Background(), serviceSources(test.src))
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: expected error %s, got %v", test.name, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: failed to parse sources: %v", test.name, err)
+			continue
+		}
+		if actual := diagnosticLines(result); strings.Join(actual, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("%s: expected diagnostics:\n%s\ngot:\n%s", test.name, strings.Join(test.expected, "\n"), strings.Join(actual, "\n"))
+		}
+		if len(result.Interfaces) != 1 {
+			t.Errorf("%s: expected schema violations to keep the interface, got %d interfaces", test.name, len(result.Interfaces))
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/seniorGolang/asti/parser/models"
+)
+
+const sourcesServiceFile = `package service
+
+import "context"
+
+// @asti name=UserService
+type UserService interface {
+	// @asti method=GetUser
+	GetUser(ctx context.Context, id string) (user User, err error)
+}
+
+type User struct {
+	ID string
+}
+`
+
+// serviceSources исходники модуля github.com/test/service с единственным файлом service/service.go
+func serviceSources(src string) (sources map[string][]byte) {
+
+	sources = map[string][]byte{
+		"go.mod":             []byte("module github.com/test/service\n\ngo 1.24\n"),
+		"service/service.go": []byte(src),
+	}
+	return
+}
+
+// parseService разбирает пакет service из исходника src парсером с опциями options
+func parseService(t *testing.T, src string, options ...Option) (result *models.Package) {
+
+	t.Helper()
+	var err error
+	if result, err = NewParser(options...).ParseSources(context.Background(), serviceSources(src)); err != nil {
+		t.Fatalf("Failed to parse sources: %v", err)
+	}
+	return
+}
+
+// roundtrip сериализует пакет в JSON и восстанавливает его обратно
+func roundtrip(t *testing.T, pkg *models.Package) (data []byte, restored *models.Package) {
+
+	t.Helper()
+	var err error
+	if data, err = NewParser().ToJSON(pkg); err != nil {
+		t.Fatalf("Failed to serialize package: %v", err)
+	}
+	if restored, err = NewParser().FromJSON(data); err != nil {
+		t.Fatalf("Failed to deserialize package: %v", err)
+	}
+	return
+}
+
+// expectation проверяемое значение разобранного пакета и его ожидаемое строковое представление
+type expectation struct {
+	name     string
+	actual   string
+	expected string
+}
+
+// checkExpectations сравнивает фактические значения с ожидаемыми
+func checkExpectations(t *testing.T, expectations []expectation) {
+
+	t.Helper()
+	for _, check := range expectations {
+		if check.actual != check.expected {
+			t.Errorf("%s: expected %q, got %q", check.name, check.expected, check.actual)
+		}
+	}
+}
+
+// diagnosticLines возвращает диагностики пакета в текстовом виде
+func diagnosticLines(pkg *models.Package) (lines []string) {
+
+	for _, diagnostic := range pkg.Diagnostics {
+		lines = append(lines, diagnostic.String())
+	}
+	return
+}
+
+// TestParseSources проверяет парсинг пакета из исходников в памяти
+func TestParseSources(t *testing.T) {
+	p := NewParser()
+
+	result, err := p.ParseSources(context.Background(), map[string][]byte{
+		"go.mod":             []byte("module github.com/test/sources\n\ngo 1.24\n"),
+		"service/service.go": []byte(sourcesServiceFile),
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse sources: %v", err)
+	}
+
+	if result.ModuleName != "github.com/test/sources" || result.PackagePath != "service" {
+		t.Errorf("Unexpected module info: %s %s", result.ModuleName, result.PackagePath)
+	}
+	if len(result.Interfaces) != 1 {
+		t.Fatalf("Expected 1 interface, got %d", len(result.Interfaces))
+	}
+	iface := result.Interfaces[0]
+	if iface.Import != "github.com/test/sources/service" {
+		t.Errorf("Expected import 'github.com/test/sources/service', got '%s'", iface.Import)
+	}
+	if iface.Position.File != "service.go" {
+		t.Errorf("Expected position file 'service.go', got '%s'", iface.Position.File)
+	}
+	if _, found := result.Types["service.User"]; !found {
+		t.Error("Type service.User not found")
+	}
+}
+
+// TestParseFS проверяет парсинг пакета из fs.FS
+func TestParseFS(t *testing.T) {
+	p := NewParser()
+
+	fsys := fstest.MapFS{
+		"go.mod":                 {Data: []byte("module github.com/test/fs\n\ngo 1.24\n")},
+		"internal/svc/svc.go":    {Data: []byte(sourcesServiceFile)},
+		"internal/svc/README.md": {Data: []byte("# svc\n")},
+	}
+	result, err := p.ParseFS(context.Background(), fsys, "internal/svc")
+	if err != nil {
+		t.Fatalf("Failed to parse fs: %v", err)
+	}
+
+	if result.ModuleName != "github.com/test/fs" || result.PackagePath != filepath.Join("internal", "svc") {
+		t.Errorf("Unexpected module info: %s %s", result.ModuleName, result.PackagePath)
+	}
+	if len(result.Interfaces) != 1 || len(result.Interfaces[0].Methods) != 1 {
+		t.Fatalf("Expected 1 interface with 1 method, got %+v", result.Interfaces)
+	}
+
+	if _, err = p.ParseFS(context.Background(), fsys, "missing"); err == nil {
+		t.Error("Expected error for missing directory, got nil")
+	}
+}
+
+// TestParsePackageWithOverlay проверяет подмену файлов на диске содержимым из памяти
+func TestParsePackageWithOverlay(t *testing.T) {
+
+	overlay := map[string][]byte{
+		"../examples/sample/sample_service.go": []byte(`// @asti version=overlay
+package examples
+
+import "context"
+
+// @asti name="OverlayService"
+type OverlayService interface {
+	Ping(ctx context.Context) (err error)
+}
+`),
+	}
+	p := NewParser(WithOverlay(overlay))
+
+	result, err := p.ParsePackage(context.Background(), "../examples/sample")
+	if err != nil {
+		t.Fatalf("Failed to parse package: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, iface := range result.Interfaces {
+		names[iface.Name] = true
+	}
+	if !names["OverlayService"] {
+		t.Error("Interface OverlayService from overlay not found")
+	}
+	if names["UserService"] {
+		t.Error("Interface UserService from shadowed file should not be found")
+	}
+	if !names["AdvancedService"] {
+		t.Error("Interface AdvancedService from disk not found")
+	}
+}
@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/seniorGolang/asti/parser/models"
+	"github.com/seniorGolang/asti/parser/pipeline"
+)
+
+// stageEdit изменение набора этапов, заданное опцией парсера
+type stageEdit func(stages *pipeline.Pipeline) (err error)
+
+// WithStages заменяет набор этапов по умолчанию на stages. Опции встроенных этапов (WithTypeChecking,
+// WithFilterRules, WithValidationRules, WithAnnotationSchema, WithAnnotationInheritance) вместе с ней
+// возвращают ошибку конфигурации при разборе пакета: нужные этапы передаются в stages явно.
+func WithStages(stages ...pipeline.Stage) Option {
+	return func(parser *Parser) {
+		parser.stages = stages
+		parser.customStages = true
+	}
+}
+
+// WithStageBefore вставляет этап stage перед этапом с именем name
+func WithStageBefore(name string, stage pipeline.Stage) Option {
+	return func(parser *Parser) {
+		parser.customStages = true
+		parser.stageEdits = append(parser.stageEdits, func(stages *pipeline.Pipeline) (err error) {
+			err = stages.InsertBefore(name, stage)
+			return
+		})
+	}
+}
+
+// WithStageAfter вставляет этап stage после этапа с именем name
+func WithStageAfter(name string, stage pipeline.Stage) Option {
+	return func(parser *Parser) {
+		parser.customStages = true
+		parser.stageEdits = append(parser.stageEdits, func(stages *pipeline.Pipeline) (err error) {
+			err = stages.InsertAfter(name, stage)
+			return
+		})
+	}
+}
+
+// WithoutStage убирает из pipeline этап с именем name
+func WithoutStage(name string) Option {
+	return func(parser *Parser) {
+		parser.stageEdits = append(parser.stageEdits, func(stages *pipeline.Pipeline) (err error) {
+			err = stages.RemoveStage(name)
+			return
+		})
+	}
+}
+
+// WithFilterRules заменяет правила фильтрации интерфейсов по умолчанию
+func WithFilterRules(rules ...pipeline.FilterRule) Option {
+	return func(parser *Parser) {
+		parser.filterRules = rules
+	}
+}
+
+// WithValidationRules добавляет этап валидации после фильтрации; без правил используются правила по умолчанию
+func WithValidationRules(rules ...pipeline.ValidationRule) Option {
+	return func(parser *Parser) {
+		parser.validation = true
+		parser.validationRules = rules
+	}
+}
+
+// WithHooks добавляет обработчики жизненного цикла этапов pipeline
+func WithHooks(hooks ...pipeline.Hooks) Option {
+	return func(parser *Parser) {
+		parser.hooks = append(parser.hooks, hooks...)
+	}
+}
+
+// WithMiddleware добавляет middleware, оборачивающие выполнение каждого этапа
+func WithMiddleware(middleware ...pipeline.Middleware) Option {
+	return func(parser *Parser) {
+		parser.middleware = append(parser.middleware, middleware...)
+	}
+}
+
+// WithAnnotationSchema добавляет этап проверки аннотаций по схеме перед сериализацией. Ошибка
+// schema.Check возвращается при разборе пакета, как и ошибки конфигурации этапов.
+func WithAnnotationSchema(schema *models.AnnotationSchema) Option {
+	return func(parser *Parser) {
+		parser.schema = schema
+	}
+}
+
+// WithAnnotationInheritance включает этап inheritance: действующие аннотации элементов (Effective)
+// наследуются по цепочкам пакет → интерфейс → метод и тип → поле по правилам rules,
+// а Provenance сообщает уровень каждого значения. Неизвестный режим возвращается ошибкой при разборе пакета.
+func WithAnnotationInheritance(rules models.InheritanceRules) Option {
+	return func(parser *Parser) {
+		parser.inheritance = &rules
+	}
+}
+
+// buildPipeline собирает pipeline из этапов по умолчанию (или WithStages) и изменений опций
+func (p *Parser) buildPipeline() {
+
+	stages := p.stages
+	if stages == nil {
+		stages = []pipeline.Stage{
+			pipeline.NewStageModule(),
+			pipeline.NewStageAST(p.annotationParser, p.namespaces...),
+			pipeline.NewStageFilter(p.filterRules...),
+		}
+		if p.validation {
+			stages = append(stages, pipeline.NewStageValidation(p.validationRules...))
+		}
+		stages = append(stages, pipeline.NewStageTypeCollection(p.annotationParser, p.namespaces...))
+		if p.typeChecking {
+			stages = append(stages, pipeline.NewStageTypeCheck())
+		}
+		if p.inheritance != nil {
+			stages = append(stages, pipeline.NewStageInheritance(*p.inheritance))
+		}
+		if p.schema != nil {
+			stages = append(stages, pipeline.NewStageSchema(p.schema))
+		}
+		stages = append(stages, pipeline.NewStageSerialization())
+	}
+	p.pipeline = pipeline.NewPipeline(stages...)
+	p.configErr = nil
+	if err := p.checkOptions(); err != nil {
+		p.configErr = fmt.Errorf("invalid pipeline configuration: %w", err)
+	}
+	for _, edit := range p.stageEdits {
+		if p.configErr != nil {
+			break
+		}
+		if err := edit(p.pipeline); err != nil {
+			p.configErr = fmt.Errorf("invalid pipeline configuration: %w", err)
+		}
+	}
+	p.pipeline.AddHooks(p.hooks...)
+	p.pipeline.Use(p.middleware...)
+	p.pipeline.Instrument(p.tracerProvider, p.meterProvider)
+}
+
+// checkOptions проверяет схему аннотаций и правила наследования, заданные опциями парсера, и
+// отсутствие опций встроенных этапов рядом с WithStages, которые иначе были бы проигнорированы
+func (p *Parser) checkOptions() (err error) {
+
+	if p.stages != nil {
+		var ignored []string
+		if p.typeChecking {
+			ignored = append(ignored, "WithTypeChecking")
+		}
+		if len(p.filterRules) > 0 {
+			ignored = append(ignored, "WithFilterRules")
+		}
+		if p.validation {
+			ignored = append(ignored, "WithValidationRules")
+		}
+		if p.schema != nil {
+			ignored = append(ignored, "WithAnnotationSchema")
+		}
+		if p.inheritance != nil {
+			ignored = append(ignored, "WithAnnotationInheritance")
+		}
+		if len(ignored) > 0 {
+			err = fmt.Errorf("WithStages replaces the default stages, %s cannot be applied; add the corresponding stages explicitly", strings.Join(ignored, ", "))
+			return
+		}
+	}
+	if p.schema != nil {
+		if err = p.schema.Check(); err != nil {
+			return
+		}
+	}
+	if p.inheritance != nil {
+		err = p.inheritance.Check()
+	}
+	return
+}
+
+// Stages возвращает имена этапов pipeline парсера в порядке выполнения
+func (p *Parser) Stages() (names []string) {
+
+	for _, stage := range p.pipeline.Stages() {
+		names = append(names, pipeline.StageName(stage))
+	}
+	return
+}
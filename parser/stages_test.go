@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seniorGolang/asti/parser/models"
+	"github.com/seniorGolang/asti/parser/pipeline"
+)
+
+// stagesSources пакет с интерфейсом без context.Context, который отбрасывает фильтр по умолчанию
+var stagesSources = map[string][]byte{
+	"go.mod":             []byte("module github.com/test/stages\n\ngo 1.24\n"),
+	"service/service.go": []byte(sourcesServiceFile),
+	"service/plain.go": []byte(`package service
+
+// @asti name=PlainService
+type PlainService interface {
+	Ping() (err error)
+}
+`),
+}
+
+// TestStagesDefault проверяет набор этапов по умолчанию и его сохранение после SetAnnotationPrefix
+func TestStagesDefault(t *testing.T) {
+
+	expected := []string{
+		pipeline.StageNameModule,
+		pipeline.StageNameAST,
+		pipeline.StageNameFilter,
+		pipeline.StageNameTypes,
+		pipeline.StageNameTypeCheck,
+		pipeline.StageNameSerialization,
+	}
+	p := NewParser(WithTypeChecking())
+	if stages := p.Stages(); !reflect.DeepEqual(stages, expected) {
+		t.Fatalf("Expected stages %v, got %v", expected, stages)
+	}
+	p.SetAnnotationPrefix("@custom")
+	if stages := p.Stages(); !reflect.DeepEqual(stages, expected) {
+		t.Errorf("Expected SetAnnotationPrefix to keep stages %v, got %v", expected, stages)
+	}
+}
+
+// TestStagesOptions проверяет вставку, удаление этапов и замену правил фильтрации
+func TestStagesOptions(t *testing.T) {
+
+	var visited []string
+	tracker := pipeline.Named("tracker", pipeline.StageFunc(func(ctx context.Context, data pipeline.Data) (result pipeline.Data, err error) {
+
+		for _, iface := range data.Interfaces {
+			visited = append(visited, iface.Name)
+		}
+		result = data
+		return
+	}))
+	p := NewParser(
+		WithStageAfter(pipeline.StageNameFilter, tracker),
+		WithFilterRules(&pipeline.AnnotationFilterRule{}),
+	)
+	expected := []string{
+		pipeline.StageNameModule,
+		pipeline.StageNameAST,
+		pipeline.StageNameFilter,
+		"tracker",
+		pipeline.StageNameTypes,
+		pipeline.StageNameSerialization,
+	}
+	if stages := p.Stages(); !reflect.DeepEqual(stages, expected) {
+		t.Fatalf("Expected stages %v, got %v", expected, stages)
+	}
+	result, err := p.ParseSources(context.Background(), stagesSources)
+	if err != nil {
+		t.Fatalf("Failed to parse sources: %v", err)
+	}
+	if len(result.Interfaces) != 2 || len(visited) != 2 {
+		t.Errorf("Expected interfaces without context to pass custom filter rules, got %d interfaces and %v", len(result.Interfaces), visited)
+	}
+
+	result, err = NewParser(WithoutStage(pipeline.StageNameFilter)).ParseSources(context.Background(), stagesSources)
+	if err != nil {
+		t.Fatalf("Failed to parse sources without filter: %v", err)
+	}
+	if len(result.Interfaces) != 2 {
+		t.Errorf("Expected 2 interfaces without filter stage, got %d", len(result.Interfaces))
+	}
+}
+
+// TestStagesValidation проверяет подключение этапа валидации с диагностиками
+func TestStagesValidation(t *testing.T) {
+
+	p := NewParser(WithoutStage(pipeline.StageNameFilter), WithValidationRules())
+	result, err := p.ParseSources(context.Background(), stagesSources)
+	if err != nil {
+		t.Fatalf("Failed to parse sources: %v", err)
+	}
+	if len(result.Interfaces) != 1 || result.Interfaces[0].Name != "UserService" {
+		t.Errorf("Expected only UserService after validation, got %+v", result.Interfaces)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Code != models.CodeValidation {
+		t.Errorf("Expected validation diagnostic for PlainService, got %v", result.Diagnostics)
+	}
+}
+
+// TestStagesUnknownName проверяет ошибку при ссылке на несуществующий этап
+func TestStagesUnknownName(t *testing.T) {
+
+	p := NewParser(WithStageBefore("missing", pipeline.NewStageValidation()))
+	_, err := p.ParseSources(context.Background(), stagesSources)
+	if err == nil || !strings.Contains(err.Error(), `stage "missing" not found`) {
+		t.Errorf("Expected unknown stage error, got %v", err)
+	}
+}
+
+// TestStagesCustomConflicts проверяет ошибку конфигурации для опций встроенных этапов рядом с WithStages
+func TestStagesCustomConflicts(t *testing.T) {
+
+	stages := WithStages(
+		pipeline.NewStageModule(),
+		pipeline.NewStageAST(models.NewAnnotationParser("@asti")),
+		pipeline.NewStageTypeCollection(models.NewAnnotationParser("@asti")),
+		pipeline.NewStageSerialization(),
+	)
+	for name, option := range map[string]Option{
+		"WithTypeChecking":          WithTypeChecking(),
+		"WithFilterRules":           WithFilterRules(&pipeline.AnnotationFilterRule{}),
+		"WithValidationRules":       WithValidationRules(),
+		"WithAnnotationSchema":      WithAnnotationSchema(&models.AnnotationSchema{}),
+		"WithAnnotationInheritance": WithAnnotationInheritance(models.InheritanceRules{}),
+	} {
+		_, err := NewParser(stages, option).ParseSources(context.Background(), stagesSources)
+		if err == nil || !strings.Contains(err.Error(), "WithStages replaces the default stages, "+name+" cannot be applied") {
+			t.Errorf("%s: expected configuration error, got %v", name, err)
+		}
+	}
+	result, err := NewParser(stages).ParseSources(context.Background(), stagesSources)
+	if err != nil {
+		t.Fatalf("Failed to parse sources with custom stages: %v", err)
+	}
+	if len(result.Interfaces) != 2 {
+		t.Errorf("Expected 2 interfaces without filter stage, got %d", len(result.Interfaces))
+	}
+}
+
+// TestStagesErrorHook проверяет, что обработанная хуком ошибка этапа не оставляет разбор без пакета
+func TestStagesErrorHook(t *testing.T) {
+
+	var handled []string
+	p := NewParser(WithHooks(pipeline.Hooks{
+		OnError: func(ctx context.Context, event pipeline.StageEvent) (err error) {
+			handled = append(handled, event.Stage)
+			return
+		},
+	}))
+	result, err := p.ParseSources(context.Background(), map[string][]byte{
+		"go.mod":             []byte("module github.com/test/stages\n\ngo 1.24\n"),
+		"service/service.go": []byte("package service\n\ntype Broken interface {\n"),
+	})
+	if err != nil || result == nil {
+		t.Fatalf("Expected handled stage errors to return a package, got %v and %v", result, err)
+	}
+	if result.PackagePath == "" || len(result.Interfaces) != 0 || len(handled) == 0 {
+		t.Errorf("Expected empty package after handled errors %v, got %+v", handled, result)
+	}
+}
@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/seniorGolang/asti/parser/models"
+	"github.com/seniorGolang/asti/parser/pipeline"
+)
+
+const attributeCacheHit = attribute.Key("asti.cache.hit")
+
+// parserTelemetry спаны и метрики вызовов разбора пакета
+type parserTelemetry struct {
+	tracer        trace.Tracer
+	parseDuration metric.Float64Histogram
+	parseErrors   metric.Int64Counter
+}
+
+func newParserTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (t *parserTelemetry) {
+
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+	meter := meterProvider.Meter(pipeline.InstrumentationName)
+	t = &parserTelemetry{tracer: tracerProvider.Tracer(pipeline.InstrumentationName)}
+	var err error
+	if t.parseDuration, err = meter.Float64Histogram("asti.parse.duration",
+		metric.WithDescription("Duration of parsing a package"), metric.WithUnit("s")); err != nil {
+		otel.Handle(err)
+	}
+	if t.parseErrors, err = meter.Int64Counter("asti.parse.errors",
+		metric.WithDescription("Number of failed package parses")); err != nil {
+		otel.Handle(err)
+	}
+	return
+}
+
+// traced выполняет разбор пакета внутри спана asti.ParsePackage и записывает метрики
+func (p *Parser) traced(ctx context.Context, packagePath string, parse func(ctx context.Context) (result *models.Package, err error)) (result *models.Package, err error) {
+
+	ctx, span := p.telemetry.tracer.Start(ctx, "asti.ParsePackage", trace.WithAttributes(pipeline.AttributePackagePath.String(packagePath)))
+	defer span.End()
+
+	started := time.Now()
+	if err = p.configErr; err == nil {
+		if result, err = parse(ctx); err == nil && result == nil {
+			err = errors.New("parse returned no package")
+		}
+	}
+	if p.telemetry.parseDuration != nil {
+		p.telemetry.parseDuration.Record(ctx, time.Since(started).Seconds())
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if p.telemetry.parseErrors != nil {
+			p.telemetry.parseErrors.Add(ctx, 1)
+		}
+		return
+	}
+	span.SetAttributes(
+		pipeline.AttributeInterfacesCount.Int(len(result.Interfaces)),
+		pipeline.AttributeTypesCount.Int(len(result.Types)),
+		pipeline.AttributeDiagnosticsCount.Int(len(result.Diagnostics)),
+	)
+	return
+}
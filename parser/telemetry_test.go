@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/seniorGolang/asti/parser/pipeline"
+)
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) (value attribute.Value, found bool) {
+
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			value, found = kv.Value, true
+			return
+		}
+	}
+	return
+}
+
+// TestTelemetry проверяет спаны и метрики разбора пакета с in-memory экспортерами
+func TestTelemetry(t *testing.T) {
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	p := NewParser(WithTracerProvider(tracerProvider), WithMeterProvider(meterProvider))
+
+	ctx := context.Background()
+	if _, err := p.ParseSources(ctx, map[string][]byte{
+		"go.mod":             []byte("module github.com/test/telemetry\n\ngo 1.24\n"),
+		"service/service.go": []byte(sourcesServiceFile),
+	}); err != nil {
+		t.Fatalf("Failed to parse sources: %v", err)
+	}
+	if _, err := p.ParsePackage(ctx, "../examples/missing"); err == nil {
+		t.Fatal("Expected error for missing package, got nil")
+	}
+
+	spans := recorder.Ended()
+	var parseSpans []sdktrace.ReadOnlySpan
+	stageSpans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		if span.Name() == "asti.ParsePackage" {
+			parseSpans = append(parseSpans, span)
+			continue
+		}
+		if stage, found := spanAttribute(span, pipeline.AttributeStage); found {
+			stageSpans[stage.AsString()] = span
+		}
+	}
+	if len(parseSpans) != 2 {
+		t.Fatalf("Expected 2 ParsePackage spans, got %d", len(parseSpans))
+	}
+	parseSpan := parseSpans[0]
+	if value, _ := spanAttribute(parseSpan, pipeline.AttributePackagePath); value.AsString() != "service" {
+		t.Errorf("Expected package path attribute 'service', got %q", value.AsString())
+	}
+	if value, _ := spanAttribute(parseSpan, pipeline.AttributeFilesCount); value.AsInt64() != 1 {
+		t.Errorf("Expected files count 1, got %d", value.AsInt64())
+	}
+	if value, _ := spanAttribute(parseSpan, pipeline.AttributeInterfacesCount); value.AsInt64() != 1 {
+		t.Errorf("Expected interfaces count 1, got %d", value.AsInt64())
+	}
+	if value, _ := spanAttribute(parseSpan, pipeline.AttributeTypesCount); value.AsInt64() == 0 {
+		t.Error("Expected non-zero types count")
+	}
+	if parseSpans[1].Status().Code.String() != "Error" {
+		t.Errorf("Expected error status for failed parse, got %v", parseSpans[1].Status())
+	}
+
+	for _, stage := range []string{pipeline.StageNameModule, pipeline.StageNameAST, pipeline.StageNameFilter, pipeline.StageNameTypes, pipeline.StageNameSerialization} {
+		span, found := stageSpans[stage]
+		if !found {
+			t.Errorf("Span for stage %s not found", stage)
+			continue
+		}
+		if span.Parent().SpanID() != parseSpan.SpanContext().SpanID() {
+			t.Errorf("Span for stage %s is not a child of the ParsePackage span", stage)
+		}
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &metrics); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	found := make(map[string]metricdata.Aggregation)
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = m.Data
+		}
+	}
+	if histogram, ok := found["asti.parse.duration"].(metricdata.Histogram[float64]); !ok || histogram.DataPoints[0].Count != 2 {
+		t.Errorf("Expected asti.parse.duration with 2 records, got %+v", found["asti.parse.duration"])
+	}
+	if counter, ok := found["asti.parse.errors"].(metricdata.Sum[int64]); !ok || counter.DataPoints[0].Value != 1 {
+		t.Errorf("Expected asti.parse.errors equal to 1, got %+v", found["asti.parse.errors"])
+	}
+	if _, ok := found["asti.stage.duration"].(metricdata.Histogram[float64]); !ok {
+		t.Errorf("Expected asti.stage.duration histogram, got %+v", found["asti.stage.duration"])
+	}
+}
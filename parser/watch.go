@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/seniorGolang/asti/parser/models"
+	"github.com/seniorGolang/asti/parser/pipeline"
+)
+
+const defaultWatchInterval = 500 * time.Millisecond
+
+// WatchCallback получает разницу состояний пакета, PackagePath в ней — абсолютный путь директории.
+// При ошибке разбора diff содержит только PackagePath, а пакет разбирается повторно на следующем проходе.
+type WatchCallback func(diff models.PackageDiff, err error)
+
+// watchedPackage последнее известное состояние пакета
+type watchedPackage struct {
+	fingerprint string
+	pkg         *models.Package
+}
+
+// Watch следит за пакетами, заданными путями или go-style шаблонами относительно текущей директории,
+// и передает в callback разницу моделей при изменении Go файлов. Изменения обнаруживаются опросом
+// с интервалом WithWatchInterval, поэтому не требуют inotify. Заново разбираются только пакеты,
+// у которых изменилось содержимое файлов. Первый проход сообщает все найденные пакеты как добавленные.
+// Watch блокируется до отмены ctx; callback вызывается последовательно из той же горутины.
+func (p *Parser) Watch(ctx context.Context, paths []string, callback WatchCallback) (err error) {
+
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	var baseDir string
+	if baseDir, err = os.Getwd(); err != nil {
+		err = fmt.Errorf("failed to get working directory: %w", err)
+		return
+	}
+	interval := p.watchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	watched := make(map[string]*watchedPackage)
+	for {
+		p.pollPackages(ctx, baseDir, paths, watched, callback)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollPackages сравнивает текущее содержимое пакетов с сохраненным и сообщает об изменениях
+func (p *Parser) pollPackages(ctx context.Context, baseDir string, paths []string, watched map[string]*watchedPackage, callback WatchCallback) {
+
+	dirs, err := p.watchedDirs(baseDir, paths)
+	if err != nil {
+		callback(models.PackageDiff{}, err)
+		return
+	}
+	var fileSystem pipeline.FileSystem = pipeline.OSFileSystem{}
+	if len(p.overlay) > 0 {
+		fileSystem = pipeline.NewOverlayFileSystem(fileSystem, p.overlay)
+	}
+	present := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		if ctx.Err() != nil {
+			return
+		}
+		present[dir] = true
+		var fingerprint string
+		if fingerprint, err = p.cacheKey(fileSystem, p.currentBuildContext(), dir); err != nil {
+			callback(models.PackageDiff{PackagePath: dir}, err)
+			continue
+		}
+		state, found := watched[dir]
+		if found && state.fingerprint == fingerprint {
+			continue
+		}
+		if !found {
+			state = &watchedPackage{}
+			watched[dir] = state
+		}
+		var pkg *models.Package
+		if pkg, err = p.ParsePackage(ctx, dir); err != nil {
+			callback(models.PackageDiff{PackagePath: dir}, fmt.Errorf("failed to parse package %s: %w", dir, err))
+			continue
+		}
+		// Отпечаток сохраняется только после успешного разбора, чтобы пакет разобрался повторно
+		state.fingerprint = fingerprint
+		diff := models.DiffPackages(state.pkg, pkg)
+		diff.PackagePath = dir
+		state.pkg = pkg
+		if !diff.Empty() {
+			callback(diff, nil)
+		}
+	}
+	for dir, state := range watched {
+		if present[dir] {
+			continue
+		}
+		delete(watched, dir)
+		if state.pkg != nil {
+			if diff := models.DiffPackages(state.pkg, nil); !diff.Empty() {
+				diff.PackagePath = dir
+				callback(diff, nil)
+			}
+		}
+	}
+}
+
+// watchedDirs раскрывает шаблоны в директории пакетов; удаленные явно заданные директории пропускаются,
+// чтобы об удалении пакета можно было сообщить
+func (p *Parser) watchedDirs(baseDir string, paths []string) (dirs []string, err error) {
+
+	existing := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !strings.Contains(path, "...") {
+			dir := path
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(baseDir, dir)
+			}
+			if _, statErr := os.Stat(dir); errors.Is(statErr, fs.ErrNotExist) {
+				continue
+			}
+		}
+		existing = append(existing, path)
+	}
+	dirs, err = expandPatterns(baseDir, existing)
+	return
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seniorGolang/asti/parser/models"
+	"github.com/seniorGolang/asti/parser/pipeline"
+)
+
+const watchServiceFileV2 = `package service
+
+import "context"
+
+// @asti name=UserService
+type UserService interface {
+	// @asti method=GetUser
+	GetUser(ctx context.Context, id string) (user User, err error)
+	// @asti method=DeleteUser
+	DeleteUser(ctx context.Context, id string) (err error)
+}
+
+type User struct {
+	ID   string
+	Name string
+}
+`
+
+// nextDiff ожидает очередную разницу от Watch
+func nextDiff(t *testing.T, diffs <-chan models.PackageDiff) (diff models.PackageDiff) {
+
+	t.Helper()
+	select {
+	case diff = <-diffs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for package diff")
+	}
+	return
+}
+
+func typeChange(diff models.PackageDiff, key string) (change models.TypeChange, found bool) {
+
+	for _, change = range diff.Types {
+		if change.Key == key {
+			found = true
+			return
+		}
+	}
+	return
+}
+
+// TestWatch проверяет разницу моделей при добавлении, изменении и удалении файлов
+func TestWatch(t *testing.T) {
+
+	packageDir := writeCacheTestModule(t)
+	p := NewParser(WithWatchInterval(20 * time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	diffs := make(chan models.PackageDiff, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Watch(ctx, []string{packageDir}, func(diff models.PackageDiff, err error) {
+			if err != nil {
+				t.Errorf("Unexpected watch error: %v", err)
+				return
+			}
+			diffs <- diff
+		})
+	}()
+
+	diff := nextDiff(t, diffs)
+	if len(diff.Interfaces) != 1 || diff.Interfaces[0].Kind != models.ChangeAdded || diff.Interfaces[0].ID != "service.UserService" {
+		t.Fatalf("Expected added UserService on start, got %+v", diff.Interfaces)
+	}
+	if change, found := typeChange(diff, "service.User"); !found || change.Kind != models.ChangeAdded {
+		t.Fatalf("Expected added type service.User on start, got %+v", diff.Types)
+	}
+	if diff.Package == nil || diff.PackagePath != packageDir {
+		t.Fatalf("Expected package state for %s, got %+v", packageDir, diff)
+	}
+
+	writeTestFile(t, filepath.Join(packageDir, "service.go"), watchServiceFileV2)
+	diff = nextDiff(t, diffs)
+	if len(diff.Interfaces) != 1 || diff.Interfaces[0].Kind != models.ChangeChanged {
+		t.Fatalf("Expected changed UserService, got %+v", diff.Interfaces)
+	}
+	methods := diff.Interfaces[0].Methods
+	if len(methods) != 1 || methods[0].Kind != models.ChangeAdded || methods[0].ID != "DeleteUser" {
+		t.Errorf("Expected added method DeleteUser, got %+v", methods)
+	}
+	if change, found := typeChange(diff, "service.User"); !found || change.Kind != models.ChangeChanged || len(change.New.Fields) != 2 {
+		t.Errorf("Expected changed type service.User with 2 fields, got %+v", diff.Types)
+	}
+
+	if err := os.Remove(filepath.Join(packageDir, "service.go")); err != nil {
+		t.Fatal(err)
+	}
+	diff = nextDiff(t, diffs)
+	if len(diff.Interfaces) != 1 || diff.Interfaces[0].Kind != models.ChangeRemoved {
+		t.Errorf("Expected removed UserService, got %+v", diff.Interfaces)
+	}
+	if change, found := typeChange(diff, "service.User"); !found || change.Kind != models.ChangeRemoved {
+		t.Errorf("Expected removed type service.User, got %+v", diff.Types)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected nil error after cancellation, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not stop after cancellation")
+	}
+	select {
+	case diff = <-diffs:
+		t.Errorf("Unexpected extra diff: %+v", diff)
+	default:
+	}
+}
+
+// TestWatchRetryAfterError проверяет, что пакет с ошибкой разбора разбирается повторно без изменения файлов
+func TestWatchRetryAfterError(t *testing.T) {
+
+	packageDir := writeCacheTestModule(t)
+	failures := 1
+	failOnce := pipeline.StageFunc(func(ctx context.Context, data pipeline.Data) (result pipeline.Data, err error) {
+
+		if failures > 0 {
+			failures--
+			err = errors.New("temporary failure")
+			return
+		}
+		result = data
+		return
+	})
+	p := NewParser(WithStageAfter(pipeline.StageNameFilter, pipeline.Named("fail-once", failOnce)))
+
+	var diffs []models.PackageDiff
+	var errs []error
+	watched := make(map[string]*watchedPackage)
+	for range 2 {
+		p.pollPackages(context.Background(), packageDir, []string{packageDir}, watched, func(diff models.PackageDiff, err error) {
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			diffs = append(diffs, diff)
+		})
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected one parse error, got %v", errs)
+	}
+	if len(diffs) != 1 || len(diffs[0].Interfaces) != 1 || diffs[0].Interfaces[0].Kind != models.ChangeAdded {
+		t.Errorf("Expected package to be added on the next poll, got %+v", diffs)
+	}
+}